@@ -1,12 +1,27 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/jessevdk/go-flags"
 	"github.com/kouheiszk/ig-crawler"
+	"github.com/kouheiszk/ig-crawler/pkg/feed"
+	"github.com/kouheiszk/ig-crawler/pkg/gallery"
+	"github.com/kouheiszk/ig-crawler/pkg/output"
+	"github.com/kouheiszk/ig-crawler/pkg/proxy"
+	"github.com/kouheiszk/ig-crawler/pkg/scheduler"
+	"github.com/kouheiszk/ig-crawler/pkg/server"
+	"github.com/kouheiszk/ig-crawler/pkg/ua"
+	"github.com/kouheiszk/ig-crawler/pkg/warc"
+	"github.com/pkg/errors"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 )
 
 const (
@@ -15,25 +30,49 @@ const (
 )
 
 type CommandLineOptions struct {
-	Type        string `short:"t" long:"type" description:"profile | posts" default:"profile"`
-	Username    string `short:"u" long:"username" description:"Target username." required:"true"`
-	Concurrency int    `short:"c" long:"concurrency" description:"Concurrency number of converting images to pdf." default:"2"`
-	Version     bool   `short:"V" long:"version" description:"Displays version information."`
+	Type        string        `short:"t" long:"type" description:"profile | posts | post | comments | followers | following | watch | deleted | feed | gallery | serve | schedule" default:"profile"`
+	Username    string        `short:"u" long:"username" description:"Target username."`
+	Hashtag     string        `long:"hashtag" description:"Target hashtag instead of a username."`
+	Location    string        `long:"location" description:"Target location id instead of a username."`
+	TopPosts    bool          `long:"top-posts" description:"Include top posts of the hashtag or location."`
+	Url         string        `long:"url" description:"Target post url or shortcode."`
+	MaxComments int           `long:"max-comments" description:"Maximum comments per post."`
+	Cookies     string        `long:"cookies" description:"cookies.txt or JSON cookie export of a logged-in session."`
+	SessionId   string        `long:"session-id" description:"Value of the sessionid cookie of a logged-in session."`
+	Checkpoint  string        `long:"checkpoint" description:"File to save and resume follower lists."`
+	State       string        `long:"state" description:"File to store crawled posts for deleted-post detection."`
+	Json        bool          `long:"json" description:"Print the full profile or profile changes as JSON."`
+	Snapshots   string        `long:"snapshots" description:"Directory to store profile snapshots." default:"snapshots"`
+	Interval    time.Duration `long:"interval" description:"Interval between profile checks in watch mode." default:"1h"`
+	Format      string        `short:"f" long:"format" description:"json | jsonl | ndjson | csv | tsv" default:"json"`
+	Output      string        `short:"o" long:"output" description:"Output file, .gz to compress. Defaults to stdout. Directory for gallery."`
+	FeedFormat  string        `long:"feed-format" description:"atom | rss" default:"atom"`
+	Warc        string        `long:"warc" description:"Directory to archive every HTTP exchange and media as WARC files."`
+	WarcSize    int64         `long:"warc-size" description:"Maximum size of a WARC file in bytes before rotating."`
+	Schedule    string        `long:"schedule" description:"JSON file of scheduled crawls for the schedule type."`
+	Listen      string        `long:"listen" description:"Address for the API server to listen on." default:":8080"`
+	QueueSize   int           `long:"queue-size" description:"Maximum number of crawls waiting in the API server." default:"100"`
+	Workers     int           `long:"workers" description:"Number of crawls the API server runs at once." default:"1"`
+	MaxConns    int           `long:"max-connections" description:"Upper limit of max_connections a crawl request to the API server can ask for." default:"4"`
+	Retention   time.Duration `long:"retention" description:"Time the API server keeps finished crawls." default:"1h"`
+	Shutdown    time.Duration `long:"shutdown-timeout" description:"Time to wait for running crawls when the API server stops." default:"30s"`
+	Columns     string        `long:"columns" description:"Comma separated CSV/TSV columns."`
+	Gzip        bool          `long:"gzip" description:"Compress the output."`
+	Progress    bool          `long:"progress" description:"Show a progress bar with an ETA on stderr."`
+	UserAgent   string        `long:"user-agent" description:"User agent to send instead of a built-in one. Rejected when it's unparsable or outdated."`
+	Browser     string        `long:"browser" description:"chrome | edge | firefox | safari, browser to present as."`
+	Platform    string        `long:"platform" description:"windows | macos | linux | android | ios, platform to present as."`
+	Proxies     []string      `long:"proxy" description:"http, https or socks5 proxy URL with optional user:password, can be given several times."`
+	ProxyFile   string        `long:"proxy-file" description:"File with one proxy URL per line."`
+	ProxyRotate string        `long:"proxy-rotation" description:"per-crawl | per-request, how to rotate proxies." default:"per-crawl"`
+	ProxyCool   time.Duration `long:"proxy-cool-down" description:"Time to take a proxy out of rotation after it keeps getting throttled." default:"10m"`
+	ProxyFails  int           `long:"proxy-max-failures" description:"Throttled responses or connection errors in a row before a proxy cools down." default:"3"`
+	UARotation  string        `long:"ua-rotation" description:"fixed | per-crawl | per-request | round-robin | weighted, how to rotate user agents of the selected browser and platform."`
+	Concurrency int           `short:"c" long:"concurrency" description:"Concurrency number of converting images to pdf." default:"2"`
+	Version     bool          `short:"V" long:"version" description:"Displays version information."`
 }
 
 func main() {
-	// -----------------------------------------------------------------------------------
-	// Handle SIGINT (Ctrl + C)
-	// -----------------------------------------------------------------------------------
-
-	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, os.Interrupt, os.Kill)
-	go func() {
-		<-signalChan
-		fmt.Println("Operation has been aborted.")
-		os.Exit(2)
-	}()
-
 	// -----------------------------------------------------------------------------------
 	// Parse arguments
 	// -----------------------------------------------------------------------------------
@@ -44,6 +83,21 @@ func main() {
 		log.Fatalln(err)
 	}
 
+	// -----------------------------------------------------------------------------------
+	// Handle SIGINT (Ctrl + C)
+	// -----------------------------------------------------------------------------------
+
+	// serveとscheduleは自分でシグナルを受けて停止する
+	if opts.Type != "serve" && opts.Type != "schedule" {
+		signalChan := make(chan os.Signal, 1)
+		signal.Notify(signalChan, os.Interrupt, os.Kill)
+		go func() {
+			<-signalChan
+			fmt.Println("Operation has been aborted.")
+			os.Exit(2)
+		}()
+	}
+
 	// -----------------------------------------------------------------------------------
 	// Handle version command
 	// -----------------------------------------------------------------------------------
@@ -53,18 +107,525 @@ func main() {
 		return
 	}
 
+	switch opts.Type {
+	case "posts":
+		if opts.Username == "" && opts.Hashtag == "" && opts.Location == "" {
+			log.Fatalln(fmt.Errorf("one of `-u, --username', `--hashtag' or `--location' must be specified"))
+		}
+	case "profile", "comments", "followers", "following", "watch", "deleted", "feed", "gallery":
+		if opts.Username == "" {
+			log.Fatalln(fmt.Errorf("the required flag `-u, --username' was not specified"))
+		}
+	case "post":
+		if opts.Url == "" {
+			log.Fatalln(fmt.Errorf("the required flag `--url' was not specified"))
+		}
+	case "schedule":
+		if opts.Schedule == "" {
+			log.Fatalln(fmt.Errorf("the required flag `--schedule' was not specified"))
+		}
+	}
+
+	if opts.Type == "feed" && opts.FeedFormat != "atom" && opts.FeedFormat != "rss" {
+		log.Fatalln(fmt.Errorf("invalid feed format: %s", opts.FeedFormat))
+	}
+
+	// -----------------------------------------------------------------------------------
+	// Select browser profile
+	// -----------------------------------------------------------------------------------
+
+	browser, platform := ua.Browser(strings.ToLower(opts.Browser)), ua.Platform(strings.ToLower(opts.Platform))
+
+	var profile *ua.Profile
+	if opts.UserAgent != "" {
+		if opts.Browser != "" || opts.Platform != "" || opts.UARotation != "" {
+			log.Fatalln(fmt.Errorf("--user-agent can't be combined with --browser, --platform or --ua-rotation"))
+		}
+		profile, err = ua.ProfileFor(opts.UserAgent)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	} else if opts.Browser != "" || opts.Platform != "" {
+		profile, err = ua.RandomProfile(browser, platform)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	var rotator *ua.Rotator
+	if opts.UARotation != "" {
+		strategy, err := ua.ParseStrategy(opts.UARotation)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		rotator, err = ua.NewRotator(strategy, ua.SelectProfiles(browser, platform), time.Now().UnixNano())
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	// -----------------------------------------------------------------------------------
+	// Load proxies
+	// -----------------------------------------------------------------------------------
+
+	var proxies *proxy.Pool
+	if opts.ProxyFile != "" {
+		urls, err := proxy.ReadList(opts.ProxyFile)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		opts.Proxies = append(opts.Proxies, urls...)
+	}
+	if len(opts.Proxies) > 0 {
+		rotation, err := proxy.ParseRotation(opts.ProxyRotate)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		proxies, err = proxy.New(opts.Proxies, proxy.Options{Rotation: rotation, MaxFailures: opts.ProxyFails, CoolDown: opts.ProxyCool})
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	// -----------------------------------------------------------------------------------
+	// Load session
+	// -----------------------------------------------------------------------------------
+
+	var session *crawler.Session
+	if opts.Cookies != "" {
+		session, err = crawler.LoadSession(opts.Cookies)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	} else if opts.SessionId != "" {
+		session = crawler.NewSession([]*http.Cookie{{Name: crawler.SessionCookieName, Value: opts.SessionId}})
+	}
+
+	// -----------------------------------------------------------------------------------
+	// Open WARC archive
+	// -----------------------------------------------------------------------------------
+
+	var archive *warc.Writer
+	if opts.Warc != "" {
+		archive, err = warc.NewWriter(opts.Warc, Name, warc.Options{MaxSize: opts.WarcSize, Gzip: true, Software: Name + "/" + Version})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer archive.Close()
+	}
+
+	// serveでは /metrics で公開し、それ以外は終了時に要約を出す
+	stats := crawler.NewMetrics()
+
+	var progress crawler.ProgressReporter
+	var bar *progressBar
+	if opts.Progress && opts.Type != "serve" && opts.Type != "schedule" {
+		bar = newProgressBar(os.Stderr)
+		progress = bar
+		log.SetOutput(bar)
+	}
+
 	switch opts.Type {
 	case "profile":
+		if opts.Json {
+			profile, err := crawler.FetchProfile(&crawler.Config{
+				Username: opts.Username,
+				Session:  session,
+				Warc:     archive,
+				Metrics:  stats,
+				Profile:  profile,
+				Rotator:  rotator,
+				Proxies:  proxies,
+				Progress: progress,
+			})
+			if err != nil {
+				log.Fatalln(err)
+			}
+			bytes, err := json.MarshalIndent(profile, "", "  ")
+			if err != nil {
+				log.Fatalln(err)
+			}
+			fmt.Println(string(bytes))
+			break
+		}
 		url, err := crawler.FetchProfileImage(&crawler.Config{
 			Username: opts.Username,
+			Session:  session,
+			Warc:     archive,
+			Metrics:  stats,
+			Profile:  profile,
+			Rotator:  rotator,
+			Proxies:  proxies,
+			Progress: progress,
 		})
 		if err != nil {
 			log.Fatalln(err)
 		}
 		fmt.Println(url)
 	case "posts":
-		fmt.Println("posts")
+		writer := newWriter(&opts)
+		_, err := crawler.FetchResources(&crawler.Config{
+			Username:       opts.Username,
+			Hashtag:        opts.Hashtag,
+			Location:       opts.Location,
+			TopPosts:       opts.TopPosts,
+			MaxConnections: opts.Concurrency,
+			Session:        session,
+			Warc:           archive,
+			Metrics:        stats,
+			Profile:        profile,
+			Rotator:        rotator,
+			Proxies:        proxies,
+			Progress:       progress,
+			OnResource: func(resource crawler.Resource) {
+				if err := writer.Write(resource); err != nil {
+					log.Fatalln(err)
+				}
+			},
+		})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if err = writer.Close(); err != nil {
+			log.Fatalln(err)
+		}
+	case "comments":
+		posts, err := crawler.FetchPosts(&crawler.Config{
+			Username:       opts.Username,
+			MaxConnections: opts.Concurrency,
+			Comments:       true,
+			MaxComments:    opts.MaxComments,
+			Session:        session,
+			Warc:           archive,
+			Metrics:        stats,
+			Profile:        profile,
+			Rotator:        rotator,
+			Proxies:        proxies,
+			Progress:       progress,
+		})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		writer := newWriter(&opts)
+		for _, comment := range crawler.FlattenComments(posts) {
+			if err = writer.Write(comment); err != nil {
+				log.Fatalln(err)
+			}
+		}
+		if err = writer.Close(); err != nil {
+			log.Fatalln(err)
+		}
+	case "followers", "following":
+		fetch := crawler.FetchFollowers
+		if opts.Type == "following" {
+			fetch = crawler.FetchFollowing
+		}
+		accounts, err := fetch(&crawler.Config{
+			Username:       opts.Username,
+			MaxConnections: opts.Concurrency,
+			Checkpoint:     opts.Checkpoint,
+			Session:        session,
+			Warc:           archive,
+			Metrics:        stats,
+			Profile:        profile,
+			Rotator:        rotator,
+			Proxies:        proxies,
+			Progress:       progress,
+		})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		writer := newWriter(&opts)
+		for _, account := range accounts {
+			if err = writer.Write(account); err != nil {
+				log.Fatalln(err)
+			}
+		}
+		if err = writer.Close(); err != nil {
+			log.Fatalln(err)
+		}
+	case "post":
+		post, err := crawler.FetchPost(context.Background(), &crawler.Config{
+			Comments:    opts.MaxComments > 0,
+			MaxComments: opts.MaxComments,
+			Session:     session,
+			Warc:        archive,
+			Metrics:     stats,
+			Profile:     profile,
+			Rotator:     rotator,
+			Proxies:     proxies,
+			Progress:    progress,
+		}, opts.Url)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		bytes, err := json.MarshalIndent(post, "", "  ")
+		if err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Println(string(bytes))
+	case "deleted":
+		state := opts.State
+		if state == "" {
+			state = opts.Username + ".posts.json"
+		}
+		deleted, err := crawler.FetchDeletedPosts(context.Background(), &crawler.Config{
+			Username:       opts.Username,
+			MaxConnections: opts.Concurrency,
+			Session:        session,
+			Warc:           archive,
+			Metrics:        stats,
+			Profile:        profile,
+			Rotator:        rotator,
+			Proxies:        proxies,
+			Progress:       progress,
+		}, state)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		writer := newWriter(&opts)
+		for _, event := range deleted {
+			if err = writer.Write(event); err != nil {
+				log.Fatalln(err)
+			}
+		}
+		if err = writer.Close(); err != nil {
+			log.Fatalln(err)
+		}
+	case "feed":
+		config := &crawler.Config{
+			Username:       opts.Username,
+			MaxConnections: opts.Concurrency,
+			Session:        session,
+			Warc:           archive,
+			Metrics:        stats,
+			Profile:        profile,
+			Rotator:        rotator,
+			Proxies:        proxies,
+			Progress:       progress,
+		}
+		profile, err := crawler.FetchProfile(config)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		posts, err := crawler.FetchPosts(config)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		f := feed.FromPosts(profile, posts)
+		var bytes []byte
+		if opts.FeedFormat == "rss" {
+			bytes, err = f.RSS()
+		} else {
+			bytes, err = f.Atom()
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if err = writeFile(opts.Output, bytes); err != nil {
+			log.Fatalln(err)
+		}
+	case "gallery":
+		config := &crawler.Config{
+			Username:       opts.Username,
+			MaxConnections: opts.Concurrency,
+			Session:        session,
+			Warc:           archive,
+			Metrics:        stats,
+			Profile:        profile,
+			Rotator:        rotator,
+			Proxies:        proxies,
+			Progress:       progress,
+		}
+		profile, err := crawler.FetchProfile(config)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		posts, err := crawler.FetchPosts(config)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		dir := opts.Output
+		if dir == "" {
+			dir = opts.Username
+		}
+		written, err := gallery.New(dir, crawler.NewClient(config)).Generate(context.Background(), profile, posts)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		for _, name := range written {
+			fmt.Println(name)
+		}
+	case "serve":
+		serve(&crawler.Config{
+			MaxConnections: opts.Concurrency,
+			Session:        session,
+			Warc:           archive,
+			Metrics:        stats,
+			Profile:        profile,
+			Rotator:        rotator,
+			Proxies:        proxies,
+		}, &opts)
+	case "schedule":
+		config, err := scheduler.LoadConfig(opts.Schedule)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		s, err := scheduler.New(config, &crawler.Config{
+			MaxConnections: opts.Concurrency,
+			Session:        session,
+			Warc:           archive,
+			Metrics:        stats,
+			Profile:        profile,
+			Rotator:        rotator,
+			Proxies:        proxies,
+		})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		s.Run(signalContext())
+	case "watch":
+		watch(&crawler.Config{
+			Username: opts.Username,
+			Session:  session,
+			Warc:     archive,
+			Metrics:  stats,
+			Profile:  profile,
+			Rotator:  rotator,
+			Proxies:  proxies,
+			Progress: progress,
+		}, crawler.NewSnapshotStore(opts.Snapshots), opts.Interval, opts.Json)
 	default:
 		log.Fatalln(fmt.Errorf("invalid type: %s", opts.Type))
 	}
+
+	if bar != nil {
+		bar.Finish()
+	}
+	if opts.Type != "serve" {
+		stats.WriteSummary(os.Stderr)
+	}
+}
+
+func newWriter(opts *CommandLineOptions) output.Writer {
+	format, err := output.ParseFormat(opts.Format)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	options := output.Options{Format: format, Gzip: opts.Gzip}
+	if opts.Columns != "" {
+		options.Columns = strings.Split(opts.Columns, ",")
+	}
+
+	var writer output.Writer
+	if opts.Output == "" || opts.Output == "-" {
+		writer, err = output.New(os.Stdout, options)
+	} else {
+		writer, err = output.Create(opts.Output, options)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	return writer
+}
+
+func writeFile(path string, bytes []byte) error {
+	if path == "" || path == "-" {
+		_, err := os.Stdout.Write(bytes)
+		return err
+	}
+
+	return output.WriteFileAtomic(path, bytes, 0644)
+}
+
+func serve(config *crawler.Config, opts *CommandLineOptions) {
+	s := server.New(server.Options{
+		QueueSize:      opts.QueueSize,
+		Workers:        opts.Workers,
+		Retention:      opts.Retention,
+		MaxConnections: opts.MaxConns,
+		Config:         config,
+	})
+	httpServer := &http.Server{Addr: opts.Listen, Handler: s}
+
+	go func() {
+		log.Printf("listening on %s", opts.Listen)
+		if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatalln(err)
+		}
+	}()
+
+	<-signalContext().Done()
+	log.Println("shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Shutdown)
+	defer cancel()
+
+	// 先にクロールを終わらせて、リソースのストリームを閉じてからHTTPサーバーを止める
+	if err := s.Shutdown(ctx); err != nil {
+		log.Println(err)
+	}
+	if err := httpServer.Shutdown(ctx); err != nil {
+		httpServer.Close()
+	}
+}
+
+// SIGINTかSIGTERMを受けたら終わるコンテキスト
+func signalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signalChan
+		cancel()
+	}()
+
+	return ctx
+}
+
+// ユーザー名が変わっても追えるよう、最初のスナップショットのユーザーIDで追跡する
+func watch(config *crawler.Config, store *crawler.SnapshotStore, interval time.Duration, asJson bool) {
+	encoder := json.NewEncoder(os.Stdout)
+	var userId string
+	for {
+		snapshot, err := crawler.FetchProfileSnapshot(config)
+		if _, ok := errors.Cause(err).(*crawler.NotFoundError); ok && userId != "" {
+			username, resolveErr := crawler.ResolveUsername(context.Background(), config, userId)
+			if resolveErr == nil && username != config.Username {
+				log.Printf("%s has been renamed to %s", config.Username, username)
+				config.Username = username
+				snapshot, err = crawler.FetchProfileSnapshot(config)
+			}
+		}
+		if err != nil {
+			log.Println(err)
+			time.Sleep(interval)
+			continue
+		}
+		userId = snapshot.Id
+
+		previous, err := store.Latest(snapshot.Id)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if previous != nil {
+			for _, change := range crawler.DiffSnapshots(previous, snapshot) {
+				if asJson {
+					encoder.Encode(change)
+				} else {
+					fmt.Println(change)
+				}
+			}
+		}
+
+		if err = store.Save(snapshot); err != nil {
+			log.Fatalln(err)
+		}
+
+		time.Sleep(interval)
+	}
 }
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"github.com/kouheiszk/ig-crawler"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const progressBarWidth = 30
+const progressInterval = 100 * time.Millisecond
+
+// 標準エラー出力の1行に投稿の進捗と残り時間を表示する。ログもこれを通して書き、行を崩さないようにする
+type progressBar struct {
+	mutex     sync.Mutex
+	w         io.Writer
+	target    string
+	tab       string
+	total     int
+	posts     int
+	resources int
+	waiting   string
+	start     time.Time
+	drawnAt   time.Time
+	now       func() time.Time
+}
+
+func newProgressBar(w io.Writer) *progressBar {
+	return &progressBar{w: w, start: time.Now(), now: time.Now}
+}
+
+func (p *progressBar) Report(event crawler.ProgressEvent) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	force := false
+	p.waiting = ""
+	switch event.Kind {
+	case crawler.ProfileLoaded:
+		p.target = event.Target
+		p.tab = event.Tab
+		p.total = event.Total
+		force = true
+	case crawler.PageFetched:
+		// タグ付けやIGTVなどの投稿は総数に含まれない
+		if event.Tab == p.tab {
+			p.posts += event.Posts
+		}
+	case crawler.ResourceEmitted:
+		p.resources++
+	case crawler.RetryScheduled:
+		p.waiting = fmt.Sprintf("waiting %s (%s)", event.Delay, event.Reason)
+		force = true
+	}
+
+	if force || p.now().Sub(p.drawnAt) >= progressInterval {
+		p.draw()
+	}
+}
+
+// log.SetOutputに渡す
+func (p *progressBar) Write(b []byte) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	io.WriteString(p.w, "\r\033[K")
+	n, err := p.w.Write(b)
+	p.draw()
+
+	return n, err
+}
+
+func (p *progressBar) Finish() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.drawnAt.IsZero() {
+		p.draw()
+		io.WriteString(p.w, "\n")
+	}
+}
+
+func (p *progressBar) draw() {
+	io.WriteString(p.w, "\r\033[K"+p.line())
+	p.drawnAt = p.now()
+}
+
+func (p *progressBar) line() string {
+	posts := p.posts
+	if p.total > 0 && posts > p.total {
+		posts = p.total
+	}
+
+	filled := 0
+	percent := 0
+	if p.total > 0 {
+		filled = progressBarWidth * posts / p.total
+		percent = 100 * posts / p.total
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+
+	line := fmt.Sprintf("%s [%s] %d/%d posts %3d%% %d resources", p.target, bar, posts, p.total, percent, p.resources)
+	if eta, ok := p.eta(posts); ok {
+		line += " ETA " + eta.String()
+	}
+	if p.waiting != "" {
+		line += " " + p.waiting
+	}
+
+	return line
+}
+
+// これまでの速さで残りの投稿を処理した場合の時間
+func (p *progressBar) eta(posts int) (time.Duration, bool) {
+	if posts == 0 || p.total == 0 {
+		return 0, false
+	}
+
+	elapsed := p.now().Sub(p.start)
+	remaining := time.Duration(float64(elapsed) * float64(p.total-posts) / float64(posts))
+
+	return remaining.Round(time.Second), true
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"github.com/kouheiszk/ig-crawler"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressBar(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	now := time.Unix(0, 0)
+	p := newProgressBar(buffer)
+	p.start = now
+	p.now = func() time.Time { return now }
+
+	p.Report(crawler.ProgressEvent{Kind: crawler.ProfileLoaded, Target: "kouheiszk", Tab: "timeline", Total: 40})
+	now = now.Add(10 * time.Second)
+	p.Report(crawler.ProgressEvent{Kind: crawler.PageFetched, Tab: "timeline", Posts: 10})
+	p.Report(crawler.ProgressEvent{Kind: crawler.PageFetched, Tab: "tagged", Posts: 12})
+	p.Report(crawler.ProgressEvent{Kind: crawler.ResourceEmitted})
+
+	line := p.line()
+	if !strings.Contains(line, "10/40 posts  25%") || !strings.Contains(line, "1 resources") || !strings.Contains(line, "ETA 30s") {
+		t.Errorf("unexpected line %q", line)
+	}
+
+	p.Report(crawler.ProgressEvent{Kind: crawler.RetryScheduled, Reason: "throttled", Delay: 30 * time.Second})
+	if !strings.HasSuffix(buffer.String(), "waiting 30s (throttled)") {
+		t.Errorf("retry is not shown immediately: %q", buffer.String())
+	}
+
+	p.Write([]byte("log line\n"))
+	if !strings.Contains(buffer.String(), "\r\033[Klog line\n") {
+		t.Errorf("log line breaks the bar: %q", buffer.String())
+	}
+}
@@ -0,0 +1,199 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+)
+
+const CommentsQueryHash = "bc3296d1ce80a24b1b6e40b1e72903f5"
+const RepliesQueryHash = "1ee91c32fc020d44158a3192eda98247"
+const CommentsPerPage = 50
+
+type Comment struct {
+	Id        string    `json:"id"`
+	Shortcode string    `json:"shortcode"`
+	ParentId  string    `json:"parent_id,omitempty"`
+	Text      string    `json:"text"`
+	Timestamp int32     `json:"timestamp"`
+	LikeCount int       `json:"like_count"`
+	OwnerId   string    `json:"owner_id"`
+	Owner     string    `json:"owner"`
+	Replies   []Comment `json:"replies,omitempty"`
+}
+
+type commentPage struct {
+	shortcode string
+	cursor    string
+}
+
+// 返信を含むすべてのコメントを投稿ごとに平坦化する
+func FlattenComments(posts []Post) []Comment {
+	var comments []Comment
+	for _, post := range posts {
+		for _, comment := range post.Comments {
+			replies := comment.Replies
+			comment.Replies = nil
+			comments = append(comments, comment)
+			comments = append(comments, replies...)
+		}
+	}
+
+	return comments
+}
+
+func (c *Crawler) handleCommentPage(ctx context.Context, p commentPage) error {
+	comments, cursor, err := c.fetchCommentPage(ctx, p.shortcode, p.cursor)
+	if err != nil {
+		return err
+	}
+
+	count := c.store.addComments(p.shortcode, comments, c.config.MaxComments)
+
+	if cursor != "" && (c.config.MaxComments == 0 || count < c.config.MaxComments) {
+		c.commentPageChan <- commentPage{p.shortcode, cursor}
+	}
+
+	return nil
+}
+
+func (c *Crawler) fetchComments(ctx context.Context, shortcode string) ([]Comment, error) {
+	var comments []Comment
+	cursor := ""
+	for {
+		page, next, err := c.fetchCommentPage(ctx, shortcode, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, page...)
+		if c.config.MaxComments > 0 && len(comments) >= c.config.MaxComments {
+			return comments[:c.config.MaxComments], nil
+		}
+
+		if next == "" {
+			return comments, nil
+		}
+		cursor = next
+	}
+}
+
+func (c *Crawler) fetchCommentPage(ctx context.Context, shortcode string, cursor string) ([]Comment, string, error) {
+	params := "{\"shortcode\":\"" + shortcode + "\",\"first\":" + strconv.Itoa(CommentsPerPage) + ",\"after\":" + quote(cursor) + "}"
+	pageJson := commentPageJsonType{}
+	if err := c.query(ctx, CommentsQueryHash, params, &pageJson); err != nil {
+		return nil, "", err
+	}
+
+	edges := pageJson.Data.ShortcodeMedia.EdgeMediaToParentComment
+	comments := make([]Comment, 0, len(edges.Edges))
+	for _, element := range edges.Edges {
+		comment := newComment(shortcode, "", &element.Node)
+
+		threaded := element.Node.EdgeThreadedComments
+		for _, reply := range threaded.Edges {
+			comment.Replies = append(comment.Replies, newComment(shortcode, comment.Id, &reply.Node))
+		}
+
+		if threaded.PageInfo.HasNextPage {
+			replies, err := c.fetchReplies(ctx, shortcode, comment.Id, threaded.PageInfo.EndCursor)
+			if err != nil {
+				return nil, "", err
+			}
+			comment.Replies = append(comment.Replies, replies...)
+		}
+
+		comments = append(comments, comment)
+	}
+
+	if !edges.PageInfo.HasNextPage {
+		return comments, "", nil
+	}
+
+	return comments, edges.PageInfo.EndCursor, nil
+}
+
+func (c *Crawler) fetchReplies(ctx context.Context, shortcode string, commentId string, cursor string) ([]Comment, error) {
+	var replies []Comment
+	for {
+		params := "{\"comment_id\":\"" + commentId + "\",\"first\":" + strconv.Itoa(CommentsPerPage) + ",\"after\":" + quote(cursor) + "}"
+		pageJson := repliesPageJsonType{}
+		if err := c.query(ctx, RepliesQueryHash, params, &pageJson); err != nil {
+			return nil, err
+		}
+
+		edges := pageJson.Data.Comment.EdgeThreadedComments
+		for _, element := range edges.Edges {
+			replies = append(replies, newComment(shortcode, commentId, &element.Node))
+		}
+
+		if !edges.PageInfo.HasNextPage {
+			return replies, nil
+		}
+		cursor = edges.PageInfo.EndCursor
+	}
+}
+
+func (s *ResourceStore) addComments(shortcode string, comments []Comment, max int) int {
+	s.Lock()
+	defer s.Unlock()
+
+	stored := append(s.comments[shortcode], comments...)
+	if max > 0 && len(stored) > max {
+		stored = stored[:max]
+	}
+	s.comments[shortcode] = stored
+
+	return len(stored)
+}
+
+func newComment(shortcode string, parentId string, node *commentNodeJsonType) Comment {
+	return Comment{
+		Id:        node.Id,
+		Shortcode: shortcode,
+		ParentId:  parentId,
+		Text:      node.Text,
+		Timestamp: node.CreatedAt,
+		LikeCount: node.EdgeLikedBy.Count,
+		OwnerId:   node.Owner.Id,
+		Owner:     node.Owner.Username,
+	}
+}
+
+func quote(s string) string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
+
+type commentNodeJsonType struct {
+	Id                   string               `json:"id"`
+	Text                 string               `json:"text"`
+	CreatedAt            int32                `json:"created_at"`
+	Owner                ownerJsonType        `json:"owner"`
+	EdgeLikedBy          countJsonType        `json:"edge_liked_by"`
+	EdgeThreadedComments commentEdgesJsonType `json:"edge_threaded_comments"`
+}
+
+type commentEdgesJsonType struct {
+	Count    int              `json:"count"`
+	PageInfo pageInfoJsonType `json:"page_info"`
+	Edges    []struct {
+		Node commentNodeJsonType `json:"node"`
+	} `json:"edges"`
+}
+
+type commentPageJsonType struct {
+	Data struct {
+		ShortcodeMedia struct {
+			EdgeMediaToParentComment commentEdgesJsonType `json:"edge_media_to_parent_comment"`
+		} `json:"shortcode_media"`
+	} `json:"data"`
+}
+
+type repliesPageJsonType struct {
+	Data struct {
+		Comment struct {
+			EdgeThreadedComments commentEdgesJsonType `json:"edge_threaded_comments"`
+		} `json:"comment"`
+	} `json:"data"`
+}
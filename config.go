@@ -1,17 +1,43 @@
 package crawler
 
-import "github.com/kouheiszk/ig-crawler/pkg/ua"
+import (
+	"github.com/kouheiszk/ig-crawler/pkg/proxy"
+	"github.com/kouheiszk/ig-crawler/pkg/ua"
+	"github.com/kouheiszk/ig-crawler/pkg/warc"
+)
 
 type Config struct {
 	Username       string
+	Hashtag        string
+	Location       string // Location id
+	TopPosts       bool
 	UserAgent      string
+	Profile        *ua.Profile // Headers sent with UserAgent, derived from UserAgent when it is set to a different value
+	Rotator        *ua.Rotator // Overrides UserAgent and Profile, keeps one profile for the life of Session
 	MaxConnections int
 	After          int32 // Timestamp
+	Highlights     bool
+	Stories        bool
+	Tagged         bool
+	IGTV           bool
+	Reels          bool
+	Comments       bool
+	MaxComments    int // Maximum top-level comments per post, 0 for unlimited
+	Session        *Session
+	Checkpoint     string // File to resume follower and following lists from, accounts are appended to Checkpoint+".jsonl"
+	OnResource     func(Resource)
+	Warc           *warc.Writer // Records every HTTP exchange and the media of crawled resources
+	Metrics        *Metrics     // Can be shared by several crawls
+	Progress       ProgressReporter
+	Proxies        *proxy.Pool // Can be shared by several crawls, a throttled proxy cools down for all of them
 }
 
 func NewConfig() *Config {
+	profile, _ := ua.RandomProfile("", "")
 	return &Config{
-		UserAgent: ua.RandomUserAgent(),
+		UserAgent:      profile.UserAgent,
+		Profile:        profile,
+		MaxConnections: 1,
 	}
 }
 
@@ -25,10 +51,29 @@ func mergeConfig(dst *Config, other *Config) {
 		dst.Username = other.Username
 	}
 
+	if other.Hashtag != "" {
+		dst.Hashtag = other.Hashtag
+	}
+
+	if other.Location != "" {
+		dst.Location = other.Location
+	}
+
+	if other.TopPosts {
+		dst.TopPosts = other.TopPosts
+	}
+
 	if other.UserAgent != "" {
 		dst.UserAgent = other.UserAgent
 	}
 
+	if other.Profile != nil {
+		dst.Profile = other.Profile
+		if other.UserAgent == "" {
+			dst.UserAgent = other.Profile.UserAgent
+		}
+	}
+
 	if other.MaxConnections != 0 {
 		dst.MaxConnections = other.MaxConnections
 	}
@@ -36,4 +81,64 @@ func mergeConfig(dst *Config, other *Config) {
 	if other.After != 0 {
 		dst.After = other.After
 	}
+
+	if other.Highlights {
+		dst.Highlights = other.Highlights
+	}
+
+	if other.Stories {
+		dst.Stories = other.Stories
+	}
+
+	if other.Tagged {
+		dst.Tagged = other.Tagged
+	}
+
+	if other.IGTV {
+		dst.IGTV = other.IGTV
+	}
+
+	if other.Reels {
+		dst.Reels = other.Reels
+	}
+
+	if other.Comments {
+		dst.Comments = other.Comments
+	}
+
+	if other.MaxComments != 0 {
+		dst.MaxComments = other.MaxComments
+	}
+
+	if other.Session != nil {
+		dst.Session = other.Session
+	}
+
+	if other.Rotator != nil {
+		dst.Rotator = other.Rotator
+	}
+
+	if other.Checkpoint != "" {
+		dst.Checkpoint = other.Checkpoint
+	}
+
+	if other.OnResource != nil {
+		dst.OnResource = other.OnResource
+	}
+
+	if other.Warc != nil {
+		dst.Warc = other.Warc
+	}
+
+	if other.Metrics != nil {
+		dst.Metrics = other.Metrics
+	}
+
+	if other.Progress != nil {
+		dst.Progress = other.Progress
+	}
+
+	if other.Proxies != nil {
+		dst.Proxies = other.Proxies
+	}
 }
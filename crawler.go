@@ -8,6 +8,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
+	"github.com/kouheiszk/ig-crawler/pkg/ua"
+	"github.com/kouheiszk/ig-crawler/pkg/warc"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 	"math"
@@ -24,35 +26,50 @@ const CrawlMaximumDelay = 5000 * time.Millisecond
 
 type Crawler struct {
 	config *Config
+	client *http.Client
 
-	userId     string
-	queryId    string
-	rhxGis     string
-	sharedData sharedDataJsonType
+	userId  string
+	queryId string
+	rhxGis  string
+	user    *userJsonType
+
+	hashtag  *hashtagJsonType
+	location *locationJsonType
 
 	store      *ResourceStore
+	shortcodes sync.Map
 	wait       <-chan time.Time
 	crawlDelay time.Duration
+
+	progressMutex sync.Mutex
+	userAgentErr  error
+
+	// クロールごとに持つので、複数のクロールを並行して実行できる
+	pageChan        chan page
+	resourceChan    chan Resource
+	galleryPageChan chan Resource
+	videoPageChan   chan Resource
+	reelChan        chan reel
+	commentPageChan chan commentPage
+	followPageChan  chan followPage
 }
 
 type ResourceStore struct {
 	sync.Mutex
 	resources []Resource
+	posts     map[string]*Post
+	comments  map[string][]Comment
+	accounts  []Account
 }
 
-var pageChan = make(chan page, 1000)
-var resourceChan = make(chan Resource, 10000)
-var galleryPageChan = make(chan Resource, 1000)
-var videoPageChan = make(chan Resource, 1000)
-
 func FetchProfileImage(config *Config) (string, error) {
 	crawler := NewCrawler(config)
 
-	if err := crawler.prepareConfig(); err != nil {
+	if err := crawler.prepareConfig(context.Background()); err != nil {
 		return "", err
 	}
 
-	url := crawler.sharedData.EntryData.ProfilePage[0].GraphQL.User.ProfilePicUrl
+	url := crawler.user.ProfilePicUrl
 	if url == "" {
 		return "", fmt.Errorf("profile image missing")
 	}
@@ -61,63 +78,205 @@ func FetchProfileImage(config *Config) (string, error) {
 }
 
 func FetchResources(config *Config) ([]Resource, error) {
+	return FetchResourcesWithContext(context.Background(), config)
+}
+
+func FetchResourcesWithContext(ctx context.Context, config *Config) ([]Resource, error) {
+	crawler, err := runCrawler(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return crawler.store.resources, nil
+}
+
+func FetchPosts(config *Config) ([]Post, error) {
+	return FetchPostsWithContext(context.Background(), config)
+}
+
+func FetchPostsWithContext(ctx context.Context, config *Config) ([]Post, error) {
+	crawler, err := runCrawler(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return crawler.store.sortedPosts(), nil
+}
+
+// 1回のクロールでリソースと投稿の両方が必要な場合に使う
+func FetchResourcesAndPostsWithContext(ctx context.Context, config *Config) ([]Resource, []Post, error) {
+	crawler, err := runCrawler(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return crawler.store.resources, crawler.store.sortedPosts(), nil
+}
+
+func runCrawler(ctx context.Context, config *Config) (*Crawler, error) {
 	crawler := NewCrawler(config)
 
-	if err := crawler.prepareConfig(); err != nil {
+	// ストーリーはログインしていないと取得できない
+	if crawler.config.Stories && crawler.config.Session == nil {
+		return nil, fmt.Errorf("stories require an authenticated session")
+	}
+
+	if err := crawler.prepare(ctx); err != nil {
 		return nil, err
 	}
 
-	if err := crawler.crawl(); err != nil {
+	if err := crawler.crawl(ctx); err != nil {
 		return nil, err
 	}
 
-	return crawler.store.resources, nil
+	return crawler, nil
 }
 
 func NewCrawler(config *Config) *Crawler {
 	crawler := &Crawler{
 		config:     NewConfig(),
 		crawlDelay: CrawlInitialDelay,
-		store:      &ResourceStore{},
+		store:      newResourceStore(),
+
+		pageChan:        make(chan page, 1000),
+		resourceChan:    make(chan Resource, 10000),
+		galleryPageChan: make(chan Resource, 1000),
+		videoPageChan:   make(chan Resource, 1000),
+		reelChan:        make(chan reel, 1000),
+		commentPageChan: make(chan commentPage, 1000),
+		followPageChan:  make(chan followPage, 1000),
 	}
 
 	crawler.config.Merge(config)
+	crawler.client = newClient(crawler.config)
+
+	if rotator := crawler.config.Rotator; rotator != nil {
+		var profile *ua.Profile
+		if crawler.config.Session != nil {
+			profile = rotator.Sticky(crawler.config.Session)
+		} else {
+			profile = rotator.Crawl()
+		}
+		crawler.config.Profile = profile
+		crawler.config.UserAgent = profile.UserAgent
+	}
+
+	// 独自のUser-Agentは解析して合うヘッダを送る。古すぎるものや解析できないものではリクエストしない
+	if profile := crawler.config.Profile; profile == nil || profile.UserAgent != crawler.config.UserAgent {
+		crawler.config.Profile, crawler.userAgentErr = ua.ProfileFor(crawler.config.UserAgent)
+	}
 
 	return crawler
 }
 
-func (c *Crawler) prepareConfig() error {
-	profileUrl := "https://www.instagram.com/" + c.config.Username + "/"
-	response, err := c.fetch(profileUrl)
-	if err != nil {
-		return errors.Wrapf(err, "couldn't fetch profile page: %s", profileUrl)
+func newClient(config *Config) *http.Client {
+	client := &http.Client{
+		Timeout: RequestTimeout,
 	}
 
-	jsonString, err := extractSharedDataJsonString(response)
-	if err != nil {
-		return errors.Wrapf(err, "couldn't parse sharedData json")
+	if config.Session != nil {
+		client.Jar = config.Session
+		client.CheckRedirect = config.Session.checkRedirect
+	}
+
+	// プロキシを通した通信をアーカイブし、アーカイブへの書き込みも含めて計測する
+	if config.Proxies != nil {
+		transport := config.Proxies.Transport()
+		if config.Metrics != nil {
+			transport.OnEvent = config.Metrics.observeProxy
+		}
+		client.Transport = transport
+	}
+
+	if config.Warc != nil {
+		client.Transport = &warc.Transport{Writer: config.Warc, Base: client.Transport}
+	}
+
+	if config.Metrics != nil {
+		client.Transport = &metricsTransport{metrics: config.Metrics, base: client.Transport}
+	}
+
+	return client
+}
+
+// クロールと同じプロキシ、セッション、アーカイブを通し、同じブラウザのヘッダを付けるクライアント。ギャラリーのメディアのダウンロードなどに使う
+func NewClient(config *Config) *http.Client {
+	c := NewCrawler(config)
+	client := *c.client
+	client.Transport = &headerTransport{crawler: c, base: c.client.Transport}
+
+	return &client
+}
+
+type headerTransport struct {
+	crawler *Crawler
+	base    http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if t.crawler.userAgentErr != nil {
+		return nil, t.crawler.userAgentErr
+	}
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	// RoundTripperは渡されたリクエストを書き換えない
+	r := *request
+	r.Header = http.Header{}
+	for key, values := range request.Header {
+		r.Header[key] = append([]string(nil), values...)
+	}
+	for key, value := range t.crawler.browserHeaders(request) {
+		if r.Header.Get(key) == "" {
+			r.Header.Set(key, value)
+		}
 	}
 
-	c.queryId, err = c.extractQueryId(response)
+	return base.RoundTrip(&r)
+}
+
+func newResourceStore() *ResourceStore {
+	return &ResourceStore{
+		posts:    map[string]*Post{},
+		comments: map[string][]Comment{},
+	}
+}
+
+func (c *Crawler) prepare(ctx context.Context) error {
+	switch {
+	case c.config.Hashtag != "":
+		return c.prepareHashtag(ctx)
+	case c.config.Location != "":
+		return c.prepareLocation(ctx)
+	default:
+		return c.prepareConfig(ctx)
+	}
+}
+
+func (c *Crawler) prepareConfig(ctx context.Context) error {
+	response, sharedData, err := c.loadProfile(ctx)
 	if err != nil {
-		return errors.Wrapf(err, "couldn't find queryId")
+		return err
 	}
 
-	err = json.Unmarshal([]byte(jsonString), &c.sharedData)
+	c.queryId, err = c.extractQueryId(ctx, response)
 	if err != nil {
-		return errors.Wrapf(err, "invalid main page json \"%s\"", jsonString)
+		return errors.Wrapf(err, "couldn't find queryId")
 	}
 
-	if c.sharedData.EntryData.ProfilePage[0].GraphQL.User.IsPrivate {
+	if c.user.IsPrivate && !c.user.FollowedByViewer {
 		return fmt.Errorf("\"%s\" is private account", c.config.Username)
 	}
 
-	c.userId = c.sharedData.EntryData.ProfilePage[0].GraphQL.User.Id
+	c.userId = c.user.Id
 	if c.userId == "" {
 		return fmt.Errorf("couldn't find userId")
 	}
 
-	c.rhxGis = c.sharedData.RhxGis
+	c.rhxGis = sharedData.RhxGis
 	if c.rhxGis == "" {
 		return fmt.Errorf("couldn't find rhx-gis")
 	}
@@ -125,13 +284,85 @@ func (c *Crawler) prepareConfig() error {
 	return nil
 }
 
-func (c *Crawler) crawl() error {
-	eg, ctx := errgroup.WithContext(context.Background())
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+func (c *Crawler) loadProfile(ctx context.Context) ([]byte, *sharedDataJsonType, error) {
+	profileUrl := "https://www.instagram.com/" + c.config.Username + "/"
+	response, err := c.fetch(ctx, profileUrl)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "couldn't fetch profile page: %s", profileUrl)
+	}
+
+	jsonString, err := extractSharedDataJsonString(response)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "couldn't parse sharedData json")
+	}
+
+	sharedData, err := decodeSharedData(jsonString)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid main page json \"%s\"", jsonString)
+	}
+
+	c.user, err = sharedData.profileUser()
+	if err != nil {
+		return nil, nil, c.sessionError(err)
+	}
+
+	return response, sharedData, nil
+}
 
+func (c *Crawler) crawl(ctx context.Context) error {
 	// Setup root media
-	c.handleMedia(ctx, c.sharedData.EntryData.ProfilePage[0].GraphQL.User.Media)
+	switch {
+	case c.hashtag != nil:
+		c.report(ProgressEvent{Kind: ProfileLoaded, Tab: hashtagPage.tab(), Total: c.hashtag.Media.Count})
+		c.handleMedia(ctx, c.hashtag.Media, hashtagPage)
+		if c.config.TopPosts {
+			c.handleTopPosts(ctx, c.hashtag.TopPosts, hashtagPage)
+		}
+	case c.location != nil:
+		c.report(ProgressEvent{Kind: ProfileLoaded, Tab: locationPage.tab(), Total: c.location.Media.Count})
+		c.handleMedia(ctx, c.location.Media, locationPage)
+		if c.config.TopPosts {
+			c.handleTopPosts(ctx, c.location.TopPosts, locationPage)
+		}
+	default:
+		c.report(ProgressEvent{Kind: ProfileLoaded, Tab: timelinePage.tab(), Total: c.user.Media.Count})
+		c.handleMedia(ctx, c.user.Media, timelinePage)
+
+		if c.config.Tagged {
+			c.pageChan <- page{kind: taggedPage}
+		}
+
+		if c.config.IGTV {
+			c.pageChan <- page{kind: igtvPage}
+		}
+
+		if c.config.Reels {
+			c.pageChan <- page{kind: reelsPage}
+		}
+
+		if c.config.Highlights {
+			if err := c.handleHighlightReels(ctx); err != nil {
+				return err
+			}
+		}
+
+		if c.config.Stories {
+			c.reelChan <- reel{id: c.userId}
+		}
+	}
+
+	return c.runWorkers(ctx)
+}
+
+func (c *Crawler) runWorkers(ctx context.Context) error {
+	if c.config.Metrics != nil {
+		c.config.Metrics.track(c)
+		defer c.config.Metrics.untrack(c)
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	for i := 0; i < c.config.MaxConnections; i++ {
 		eg.Go(func() error {
@@ -166,24 +397,55 @@ func (c *Crawler) setCrawlNextDelay() {
 	if nextDelay < CrawlInitialDelay {
 		c.crawlDelay = CrawlInitialDelay
 	}
+
+	if c.config.Metrics != nil {
+		c.config.Metrics.crawlDelay.Set(c.crawlDelay.Seconds(), c.target())
+	}
 }
 
-func (c *Crawler) fetch(url string) ([]byte, error) {
-	return c.fetchWithHeaders(url, map[string]string{})
+func (c *Crawler) fetch(ctx context.Context, url string) ([]byte, error) {
+	return c.fetchWithHeaders(ctx, url, map[string]string{})
 }
 
-func (c *Crawler) fetchWithHeaders(url string, headers map[string]string) ([]byte, error) {
+func (c *Crawler) fetchWithHeaders(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
 	request, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	return c.do(ctx, request, headers)
+}
+
+func (c *Crawler) postForm(ctx context.Context, url string, form url.Values, headers map[string]string) ([]byte, error) {
+	request, err := http.NewRequest("POST", url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("content-type", "application/x-www-form-urlencoded")
+
+	return c.do(ctx, request, headers)
+}
+
+func (c *Crawler) do(ctx context.Context, request *http.Request, headers map[string]string) ([]byte, error) {
+	if c.userAgentErr != nil {
+		return nil, c.userAgentErr
+	}
+
+	request = request.WithContext(ctx)
+
 	// ヘッダを追加
-	request.Header.Set("user-agent", c.config.UserAgent)
+	for key, value := range c.browserHeaders(request) {
+		request.Header.Set(key, value)
+	}
 	for key, value := range headers {
 		request.Header.Set(key, value)
 	}
 
+	// メディアのCDNにはトークンを送らない
+	if c.config.Session != nil && isInstagramHost(request.URL.Hostname()) {
+		request.Header.Set("x-csrftoken", c.config.Session.Cookie(CsrfCookieName))
+	}
+
 	// GraphQLの場合はリクエストを遅延させる
 	if isGraphqlRequest(request) && c.wait != nil {
 		<-c.wait
@@ -193,7 +455,7 @@ func (c *Crawler) fetchWithHeaders(url string, headers map[string]string) ([]byt
 		c.wait = time.After(c.crawlDelay)
 	}
 
-	response, err := fetchWithRequest(request)
+	response, err := fetchWithClient(c.client, request, c.onRetry)
 	if err != nil {
 		return nil, err
 	}
@@ -201,13 +463,72 @@ func (c *Crawler) fetchWithHeaders(url string, headers map[string]string) ([]byt
 	return response, nil
 }
 
+func (c *Crawler) browserHeaders(request *http.Request) map[string]string {
+	profile := c.config.Profile
+	if c.config.Rotator != nil && c.config.Session == nil {
+		profile = c.config.Rotator.Request(profile)
+	}
+	if profile == nil {
+		return map[string]string{"user-agent": c.config.UserAgent}
+	}
+
+	switch requestClass(request) {
+	case "graphql", "api":
+		return profile.Headers(ua.EmptyDestination)
+	case "script":
+		return profile.Headers(ua.ScriptDestination)
+	case "media":
+		if strings.Contains(request.URL.Path, ".mp4") {
+			return profile.Headers(ua.VideoDestination)
+		}
+		return profile.Headers(ua.ImageDestination)
+	default:
+		return profile.Headers(ua.DocumentDestination)
+	}
+}
+
+// 使えるプロキシが残っていれば、すぐに別のプロキシで再試行する
+func (c *Crawler) onRetry(request *http.Request, reason string) time.Duration {
+	delay := ErrorDelay
+	if c.config.Proxies != nil && c.config.Proxies.Available() {
+		delay = ProxyRetryDelay
+	}
+
+	if c.config.Metrics != nil {
+		c.config.Metrics.observeRetry(request, reason)
+	}
+	c.report(ProgressEvent{Kind: RetryScheduled, Url: request.URL.String(), Reason: reason, Delay: delay})
+
+	return delay
+}
+
 func (c *Crawler) signatureFromParams(p string) string {
 	hasher := md5.New()
 	hasher.Write([]byte(c.rhxGis + ":" + p))
 	return hex.EncodeToString(hasher.Sum(nil))
 }
 
-func (c *Crawler) extractQueryId(response []byte) (string, error) {
+func (c *Crawler) query(ctx context.Context, queryHash string, params string, v interface{}) error {
+	queryUrl := "https://www.instagram.com/graphql/query/?query_hash=" + queryHash + "&variables=" + url.QueryEscape(params)
+	response, err := c.fetchWithHeaders(ctx, queryUrl, map[string]string{"x-instagram-gis": c.signatureFromParams(params)})
+	if err != nil {
+		return err
+	}
+
+	if c.config.Session != nil {
+		if err = checkLoginRequired(response); err != nil {
+			return err
+		}
+	}
+
+	if err = json.Unmarshal(response, v); err != nil {
+		return errors.Wrapf(err, "invalid graphql json \"%s\"", string(response))
+	}
+
+	return nil
+}
+
+func (c *Crawler) extractQueryId(ctx context.Context, response []byte) (string, error) {
 	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(response))
 	if err != nil {
 		return "", err
@@ -219,7 +540,7 @@ func (c *Crawler) extractQueryId(response []byte) (string, error) {
 		scriptUri, exists := s.Attr("src")
 		if exists && strings.Contains(scriptUri, "/ProfilePageContainer.js") {
 			scriptUrl := "https://www.instagram.com" + scriptUri
-			response, err := c.fetch(scriptUrl)
+			response, err := c.fetch(ctx, scriptUrl)
 			if err != nil {
 				findErr = errors.Wrapf(err, "couldn't fetch script: %s", scriptUrl)
 				return false
@@ -227,6 +548,10 @@ func (c *Crawler) extractQueryId(response []byte) (string, error) {
 
 			regex := regexp.MustCompile(`queryId:"([^"]+)"`)
 			result := regex.FindAllStringSubmatch(string(response), -1)
+			if len(result) < 3 {
+				findErr = fmt.Errorf("expected at least 3 queryIds in %s, found %d", scriptUrl, len(result))
+				return false
+			}
 			queryId = result[2][1]
 			return false
 		}
@@ -251,30 +576,48 @@ loop:
 		select {
 		case <-ctx.Done():
 			break loop
-		case resource := <-resourceChan:
+		case resource := <-c.resourceChan:
 			err := c.handleResource(ctx, resource)
 			if err != nil {
 				return err
 			}
 			continue
-		case resource := <-galleryPageChan:
+		case resource := <-c.galleryPageChan:
 			err := c.handleGalleryPage(ctx, resource)
 			if err != nil {
 				return err
 			}
 			continue
-		case resource := <-videoPageChan:
+		case resource := <-c.videoPageChan:
 			err := c.handleVideoPage(ctx, resource)
 			if err != nil {
 				return err
 			}
 			continue
-		case page := <-pageChan:
+		case page := <-c.pageChan:
 			err := c.handlePage(ctx, page)
 			if err != nil {
 				return err
 			}
 			continue
+		case reel := <-c.reelChan:
+			err := c.handleReel(ctx, reel)
+			if err != nil {
+				return err
+			}
+			continue
+		case page := <-c.commentPageChan:
+			err := c.handleCommentPage(ctx, page)
+			if err != nil {
+				return err
+			}
+			continue
+		case page := <-c.followPageChan:
+			err := c.handleFollowPage(ctx, page)
+			if err != nil {
+				return err
+			}
+			continue
 		default:
 			break loop
 		}
@@ -283,57 +626,86 @@ loop:
 	return nil
 }
 
-func (c *Crawler) handleMedia(ctx context.Context, m mediaJsonType) {
+func (c *Crawler) handleMedia(ctx context.Context, m mediaJsonType, kind pageKind) {
 	hasNextPage := m.PageInfo.HasNextPage
+	posts := 0
+	defer func() {
+		c.report(ProgressEvent{Kind: PageFetched, Tab: kind.tab(), Posts: posts})
+	}()
+
 	for _, element := range m.Edges {
 		if element.Node.Timestamp <= c.config.After {
 			hasNextPage = false
 			continue
 		}
 
+		// 人気投稿と最新投稿など、同じ投稿が複数回現れることがある
+		if _, loaded := c.shortcodes.LoadOrStore(kind.tab()+":"+element.Node.Code, true); loaded {
+			continue
+		}
+
+		c.store.addPost(newPostFromNode(&element.Node))
+		posts++
+
+		if c.config.Comments && element.Node.EdgeMediaToComment.Count > 0 {
+			c.commentPageChan <- commentPage{shortcode: element.Node.Code}
+		}
+
 		if !element.Node.IsVideo {
 			if element.Node.Typename == "GraphImage" {
-				resourceChan <- Resource{
+				c.resourceChan <- Resource{
 					Url:       element.Node.DisplaySrc,
 					Timestamp: element.Node.Timestamp,
 					IsVideo:   false,
+					Shortcode: element.Node.Code,
+					Tab:       kind.tab(),
 				}
 			}
 			if element.Node.Typename == "GraphSidecar" {
-				galleryPageChan <- Resource{
-					Url:       "https://www.instagram.com/p/" + element.Node.Code,
+				c.galleryPageChan <- Resource{
+					Url:       postUrl(element.Node.Code),
 					Timestamp: element.Node.Timestamp,
 					IsVideo:   false,
+					Shortcode: element.Node.Code,
+					Tab:       kind.tab(),
 				}
 			}
 		} else {
-			videoPageChan <- Resource{
-				Url:       "https://www.instagram.com/p/" + element.Node.Code,
+			c.videoPageChan <- Resource{
+				Url:       postUrl(element.Node.Code),
 				Timestamp: element.Node.Timestamp,
 				IsVideo:   true,
+				Shortcode: element.Node.Code,
+				Tab:       kind.tab(),
+				ViewCount: element.Node.VideoViewCount,
 			}
 		}
 	}
 
 	if hasNextPage {
-		pageChan <- page{m.PageInfo.EndCursor}
+		c.pageChan <- page{kind, m.PageInfo.EndCursor}
 	}
 }
 
 func (c *Crawler) handlePage(ctx context.Context, p page) error {
-	params := "{\"id\":" + string(c.userId) + ",\"first\":" + "12" + ",\"after\":\"" + p.cursor + "\"}"
-	queryUrl := "https://www.instagram.com/graphql/query/?query_hash=" + c.queryId + "&variables=" + url.QueryEscape(params)
-	response, err := c.fetchWithHeaders(queryUrl, map[string]string{"x-instagram-gis": c.signatureFromParams(params)})
-	if err != nil {
-		return err
+	switch p.kind {
+	case hashtagPage:
+		return c.handleHashtagPage(ctx, p)
+	case locationPage:
+		return c.handleLocationPage(ctx, p)
+	case taggedPage, igtvPage:
+		return c.handleTabPage(ctx, p)
+	case reelsPage:
+		return c.handleReelsPage(ctx, p)
 	}
 
+	params := "{\"id\":" + string(c.userId) + ",\"first\":" + "12" + ",\"after\":\"" + p.cursor + "\"}"
 	pageJson := pageJsonType{}
-	if err = json.Unmarshal(response, &pageJson); err != nil {
-		return errors.Wrapf(err, "invalid graphql json \"%s\"", string(response))
+	if err := c.query(ctx, c.queryId, params, &pageJson); err != nil {
+		return err
 	}
 
-	c.handleMedia(ctx, pageJson.Data.User.Media)
+	c.handleMedia(ctx, pageJson.Data.User.Media, p.kind)
 
 	return nil
 }
@@ -343,34 +715,21 @@ func (c *Crawler) handleGalleryPage(ctx context.Context, r Resource) error {
 		return nil
 	}
 
-	response, err := c.fetch(r.Url)
-	if err != nil {
-		return err
-	}
-
-	jsonString, err := extractSharedDataJsonString(response)
+	post, err := c.fetchPost(ctx, r.Url)
 	if err != nil {
 		return err
 	}
 
-	pageJson := galleryPageJsonType{}
-	if err = json.Unmarshal([]byte(jsonString), &pageJson); err != nil {
-		return errors.Wrapf(err, "invalid gallery page json \"%s\"", jsonString)
-	}
+	c.store.addPost(post)
+	c.report(ProgressEvent{Kind: GalleryExpanded, Shortcode: r.Shortcode, Children: len(post.Children)})
 
-	for _, element := range pageJson.EntryData.PostPage[0].Graphql.ShortcodeMedia.EdgeSidecarToChildren.Edges {
-		if !element.Node.IsVideo {
-			resourceChan <- Resource{
-				Url:       element.Node.DisplaySrc,
-				Timestamp: r.Timestamp,
-				IsVideo:   false,
-			}
-		} else {
-			resourceChan <- Resource{
-				Url:       element.Node.VideoUrl,
-				Timestamp: r.Timestamp,
-				IsVideo:   true,
-			}
+	for _, child := range post.Children {
+		c.resourceChan <- Resource{
+			Url:       child.MediaUrl(),
+			Timestamp: r.Timestamp,
+			IsVideo:   child.IsVideo,
+			Shortcode: r.Shortcode,
+			Tab:       r.Tab,
 		}
 	}
 
@@ -382,35 +741,48 @@ func (c *Crawler) handleVideoPage(ctx context.Context, r Resource) error {
 		return nil
 	}
 
-	response, err := c.fetch(r.Url)
-	if err != nil {
-		return err
-	}
-
-	jsonString, err := extractSharedDataJsonString(response)
+	post, err := c.fetchPost(ctx, r.Url)
 	if err != nil {
 		return err
 	}
 
-	pageJson := videoPageJsonType{}
-	if err = json.Unmarshal([]byte(jsonString), &pageJson); err != nil {
-		return errors.Wrapf(err, "invalid video page json \"%s\"", jsonString)
-	}
+	c.store.addPost(post)
 
-	resourceChan <- Resource{
-		Url:       pageJson.EntryData.PostPage[0].Graphql.ShortcodeMedia.VideoUrl,
+	c.resourceChan <- Resource{
+		Url:       post.VideoUrl,
 		Timestamp: r.Timestamp,
 		IsVideo:   true,
+		Shortcode: r.Shortcode,
+		Tab:       r.Tab,
+		ViewCount: r.ViewCount,
 	}
 
 	return nil
 }
 
 func (c *Crawler) handleResource(ctx context.Context, r Resource) error {
+	// メディアも取得してアーカイブに残す
+	if c.config.Warc != nil && r.Url != "" {
+		if _, err := c.fetch(ctx, r.Url); err != nil {
+			return err
+		}
+	}
+
 	c.store.Lock()
 	defer c.store.Unlock()
 
 	c.store.resources = append(c.store.resources, r)
+
+	if c.config.Metrics != nil {
+		c.config.Metrics.observeResource(r)
+	}
+	c.report(ProgressEvent{Kind: ResourceEmitted, Resource: &r})
+
+	// ストアのロック中に呼ぶので、コールバックは並行に呼ばれない
+	if c.config.OnResource != nil {
+		c.config.OnResource(r)
+	}
+
 	return nil
 }
 
@@ -428,7 +800,10 @@ func extractSharedDataJsonString(response []byte) (string, error) {
 	doc.Find("script").EachWithBreak(func(_ int, s *goquery.Selection) bool {
 		script := s.Text()
 		if strings.HasPrefix(script, "window._sharedData") {
-			jsonString = script[21 : len(script)-1]
+			// "window._sharedData = {...};" から代入の右辺を取り出す
+			jsonString = strings.TrimSpace(strings.TrimPrefix(script, "window._sharedData"))
+			jsonString = strings.TrimSpace(strings.TrimPrefix(jsonString, "="))
+			jsonString = strings.TrimSuffix(jsonString, ";")
 			return false
 		}
 
@@ -442,42 +817,154 @@ func extractSharedDataJsonString(response []byte) (string, error) {
 	return jsonString, nil
 }
 
+type pageKind int
+
+const (
+	timelinePage pageKind = iota
+	hashtagPage
+	locationPage
+	taggedPage
+	igtvPage
+	reelsPage
+)
+
+func (k pageKind) tab() string {
+	switch k {
+	case hashtagPage:
+		return "hashtag"
+	case locationPage:
+		return "location"
+	case taggedPage:
+		return "tagged"
+	case igtvPage:
+		return "igtv"
+	case reelsPage:
+		return "reels"
+	default:
+		return "timeline"
+	}
+}
+
 type page struct {
+	kind   pageKind
 	cursor string
 }
 
+type mediaNodeJsonType struct {
+	Typename             string          `json:"__typename"`
+	Id                   string          `json:"id"`
+	IsVideo              bool            `json:"is_video"`
+	Code                 string          `json:"shortcode"`
+	Timestamp            int32           `json:"taken_at_timestamp"`
+	DisplaySrc           string          `json:"display_url"`
+	VideoViewCount       int             `json:"video_view_count"`
+	EdgeMediaToCaption   captionJsonType `json:"edge_media_to_caption"`
+	EdgeMediaPreviewLike countJsonType   `json:"edge_media_preview_like"`
+	EdgeMediaToComment   countJsonType   `json:"edge_media_to_comment"`
+	Owner                ownerJsonType   `json:"owner"`
+}
+
 type mediaJsonType struct {
 	Count int `json:"count"`
+	Edges []struct {
+		Node mediaNodeJsonType `json:"node"`
+	} `json:"edges"`
+	PageInfo pageInfoJsonType `json:"page_info"`
+}
+
+type pageInfoJsonType struct {
+	HasNextPage bool   `json:"has_next_page"`
+	EndCursor   string `json:"end_cursor"`
+}
+
+type captionJsonType struct {
 	Edges []struct {
 		Node struct {
-			Typename   string `json:"__typename"`
-			Id         string `json:"id"`
-			IsVideo    bool   `json:"is_video"`
-			Code       string `json:"shortcode"`
-			Timestamp  int32  `json:"taken_at_timestamp"`
-			DisplaySrc string `json:"display_url"`
+			Text string `json:"text"`
 		} `json:"node"`
 	} `json:"edges"`
-	PageInfo struct {
-		HasNextPage bool   `json:"has_next_page"`
-		EndCursor   string `json:"end_cursor"`
-	} `json:"page_info"`
+}
+
+type countJsonType struct {
+	Count int `json:"count"`
+}
+
+type ownerJsonType struct {
+	Id       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type userJsonType struct {
+	Id                   string        `json:"id"`
+	Username             string        `json:"username"`
+	FullName             string        `json:"full_name"`
+	Biography            string        `json:"biography"`
+	ExternalUrl          string        `json:"external_url"`
+	EdgeFollowedBy       countJsonType `json:"edge_followed_by"`
+	EdgeFollow           countJsonType `json:"edge_follow"`
+	Media                mediaJsonType `json:"edge_owner_to_timeline_media"`
+	ProfilePicUrl        string        `json:"profile_pic_url_hd"`
+	ProfilePicUrlSmall   string        `json:"profile_pic_url"`
+	IsPrivate            bool          `json:"is_private"`
+	IsVerified           bool          `json:"is_verified"`
+	IsBusinessAccount    bool          `json:"is_business_account"`
+	BusinessCategoryName string        `json:"business_category_name"`
+	HighlightReelCount   int           `json:"highlight_reel_count"`
+	FollowedByViewer     bool          `json:"followed_by_viewer"`
+}
+
+type shortcodeMediaJsonType struct {
+	Typename              string          `json:"__typename"`
+	Id                    string          `json:"id"`
+	Shortcode             string          `json:"shortcode"`
+	IsVideo               bool            `json:"is_video"`
+	Timestamp             int32           `json:"taken_at_timestamp"`
+	DisplaySrc            string          `json:"display_url"`
+	VideoUrl              string          `json:"video_url"`
+	EdgeMediaToCaption    captionJsonType `json:"edge_media_to_caption"`
+	EdgeMediaPreviewLike  countJsonType   `json:"edge_media_preview_like"`
+	EdgeMediaToComment    countJsonType   `json:"edge_media_to_comment"`
+	Owner                 ownerJsonType   `json:"owner"`
+	EdgeSidecarToChildren struct {
+		Edges []struct {
+			Node struct {
+				Typename     string `json:"__typename"`
+				Id           string `json:"id"`
+				MediaPreview string `json:"media_preview"`
+				IsVideo      bool   `json:"is_video"`
+				DisplaySrc   string `json:"display_url"`
+				VideoUrl     string `json:"video_url"`
+			} `json:"node"`
+		} `json:"edges"`
+	} `json:"edge_sidecar_to_children"`
 }
 
 type sharedDataJsonType struct {
 	EntryData struct {
 		ProfilePage []struct {
 			GraphQL struct {
-				User struct {
-					Id            string        `json:"id"`
-					Media         mediaJsonType `json:"edge_owner_to_timeline_media"`
-					ProfilePicUrl string        `json:"profile_pic_url_hd"`
-					IsPrivate     bool          `json:"is_private"`
-				} `json:"user"`
+				User *userJsonType `json:"user"`
 			} `json:"graphql"`
 		} `json:"ProfilePage"`
+		TagPage []struct {
+			GraphQL struct {
+				Hashtag *hashtagJsonType `json:"hashtag"`
+			} `json:"graphql"`
+		} `json:"TagPage"`
+		LocationsPage []struct {
+			GraphQL struct {
+				Location *locationJsonType `json:"location"`
+			} `json:"graphql"`
+		} `json:"LocationsPage"`
+		PostPage []struct {
+			Graphql struct {
+				ShortcodeMedia *shortcodeMediaJsonType `json:"shortcode_media"`
+			} `json:"graphql"`
+		} `json:"PostPage"`
 	} `json:"entry_data"`
 	RhxGis string `json:"rhx_gis"`
+
+	raw string
 }
 
 type pageJsonType struct {
@@ -487,40 +974,3 @@ type pageJsonType struct {
 		} `json:"user"`
 	} `json:"data"`
 }
-
-type galleryPageJsonType struct {
-	EntryData struct {
-		PostPage []struct {
-			Graphql struct {
-				ShortcodeMedia struct {
-					EdgeSidecarToChildren struct {
-						Edges []struct {
-							Node struct {
-								Typename     string `json:"__typename"`
-								Id           string `json:"id"`
-								MediaPreview string `json:"media_preview"`
-								IsVideo      bool   `json:"is_video"`
-								DisplaySrc   string `json:"display_url"`
-								VideoUrl     string `json:"video_url"`
-							} `json:"node"`
-						} `json:"edges"`
-					} `json:"edge_sidecar_to_children"`
-				} `json:"shortcode_media"`
-			} `json:"graphql"`
-		} `json:"PostPage"`
-	} `json:"entry_data"`
-}
-
-type videoPageJsonType struct {
-	EntryData struct {
-		PostPage []struct {
-			Graphql struct {
-				ShortcodeMedia struct {
-					Typename string `json:"__typename"`
-					Id       string `json:"id"`
-					VideoUrl string `json:"video_url"`
-				} `json:"shortcode_media"`
-			} `json:"graphql"`
-		} `json:"PostPage"`
-	} `json:"entry_data"`
-}
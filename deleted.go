@@ -0,0 +1,118 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/kouheiszk/ig-crawler/pkg/output"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+type PostDeleted struct {
+	Post       Post      `json:"post"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// 前回のクロール結果を読み込み、今回見つからなかった投稿のうち削除されたものを返す
+func FetchDeletedPosts(ctx context.Context, config *Config, path string) ([]PostDeleted, error) {
+	previous, err := LoadPosts(path)
+	if err != nil {
+		return nil, err
+	}
+
+	crawler, err := runCrawler(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	current := crawler.store.sortedPosts()
+	deleted, err := crawler.DetectDeletedPosts(ctx, previous, current)
+	if err != nil {
+		return nil, err
+	}
+
+	// 一覧から外れただけの投稿も次回の比較のために残しておく
+	if err = SavePosts(path, mergePosts(current, previous, deleted)); err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (c *Crawler) DetectDeletedPosts(ctx context.Context, previous []Post, current []Post) ([]PostDeleted, error) {
+	seen := map[string]bool{}
+	for _, post := range current {
+		seen[post.Shortcode] = true
+	}
+
+	var deleted []PostDeleted
+	for _, post := range previous {
+		// 取得対象外の期間の投稿は比較しない
+		if seen[post.Shortcode] || post.Timestamp <= c.config.After {
+			continue
+		}
+
+		// 一覧から消えただけの可能性があるので、投稿ページが404になることを確認する
+		_, err := c.fetchPost(ctx, postUrl(post.Shortcode))
+		if err == nil {
+			continue
+		}
+		if _, ok := errors.Cause(err).(*NotFoundError); !ok {
+			return nil, err
+		}
+
+		deleted = append(deleted, PostDeleted{
+			Post:       post,
+			DetectedAt: time.Now(),
+		})
+	}
+
+	return deleted, nil
+}
+
+func mergePosts(current []Post, previous []Post, deleted []PostDeleted) []Post {
+	skip := map[string]bool{}
+	for _, post := range current {
+		skip[post.Shortcode] = true
+	}
+	for _, event := range deleted {
+		skip[event.Post.Shortcode] = true
+	}
+
+	merged := append([]Post{}, current...)
+	for _, post := range previous {
+		if !skip[post.Shortcode] {
+			merged = append(merged, post)
+		}
+	}
+
+	return merged
+}
+
+func LoadPosts(path string) ([]Post, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []Post
+	if err = json.Unmarshal(bytes, &posts); err != nil {
+		return nil, errors.Wrapf(err, "invalid posts file \"%s\"", path)
+	}
+
+	return posts, nil
+}
+
+func SavePosts(path string, posts []Post) error {
+	bytes, err := json.Marshal(posts)
+	if err != nil {
+		return err
+	}
+
+	return output.WriteFileAtomic(path, bytes, 0644)
+}
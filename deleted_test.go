@@ -0,0 +1,88 @@
+package crawler
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestMergePosts(t *testing.T) {
+	cases := []struct {
+		current  []string
+		previous []string
+		deleted  []string
+		merged   string
+	}{
+		{nil, nil, nil, ""},
+		{[]string{"a"}, []string{"a", "b"}, nil, "a,b"},
+		{[]string{"a"}, []string{"a", "b"}, []string{"b"}, "a"},
+		{[]string{"c"}, []string{"a", "b"}, []string{"a"}, "c,b"},
+	}
+
+	posts := func(shortcodes []string) []Post {
+		var posts []Post
+		for _, shortcode := range shortcodes {
+			posts = append(posts, Post{Shortcode: shortcode})
+		}
+		return posts
+	}
+
+	for _, c := range cases {
+		var deleted []PostDeleted
+		for _, post := range posts(c.deleted) {
+			deleted = append(deleted, PostDeleted{Post: post})
+		}
+
+		var shortcodes []string
+		for _, post := range mergePosts(posts(c.current), posts(c.previous), deleted) {
+			shortcodes = append(shortcodes, post.Shortcode)
+		}
+		if merged := strings.Join(shortcodes, ","); merged != c.merged {
+			t.Errorf("%v %v %v: got %q, expected %q", c.current, c.previous, c.deleted, merged, c.merged)
+		}
+	}
+}
+
+func TestDetectDeletedPosts(t *testing.T) {
+	cases := []struct {
+		shortcode string
+		status    int
+		body      string
+		deleted   bool
+		err       bool
+	}{
+		{"kept", http.StatusOK, `<script>window._sharedData = {"entry_data":{"PostPage":[{"graphql":{"shortcode_media":{"shortcode":"kept"}}}]}};</script>`, false, false},
+		{"gone", http.StatusNotFound, "", true, false},
+		{"broken", http.StatusOK, "<html></html>", false, true},
+	}
+
+	for _, c := range cases {
+		crawler := NewCrawler(&Config{After: 100})
+		crawler.client = &http.Client{Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: c.status,
+				Body:       ioutil.NopCloser(strings.NewReader(c.body)),
+				Header:     http.Header{},
+				Request:    request,
+			}, nil
+		})}
+
+		previous := []Post{{Shortcode: c.shortcode, Timestamp: 200}, {Shortcode: "old", Timestamp: 50}, {Shortcode: "current", Timestamp: 300}}
+		deleted, err := crawler.DetectDeletedPosts(context.Background(), previous, []Post{{Shortcode: "current"}})
+		if (err != nil) != c.err {
+			t.Errorf("%s: unexpected error %v", c.shortcode, err)
+			continue
+		}
+		if (len(deleted) == 1 && deleted[0].Post.Shortcode == c.shortcode) != c.deleted || len(deleted) > 1 {
+			t.Errorf("%s: unexpected deleted posts %v", c.shortcode, deleted)
+		}
+	}
+}
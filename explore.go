@@ -0,0 +1,129 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/url"
+)
+
+const HashtagQueryHash = "f92f56d47dc7a55b606908374b43a314"
+const LocationQueryHash = "1b84447a4d8b6d6d0426fefb34514485"
+
+func (c *Crawler) prepareHashtag(ctx context.Context) error {
+	tagUrl := "https://www.instagram.com/explore/tags/" + url.PathEscape(c.config.Hashtag) + "/"
+	sharedData, err := c.fetchSharedData(ctx, tagUrl)
+	if err != nil {
+		return err
+	}
+
+	c.hashtag, err = sharedData.hashtag()
+	if err != nil {
+		return err
+	}
+
+	c.rhxGis = sharedData.RhxGis
+	if c.rhxGis == "" {
+		return fmt.Errorf("couldn't find rhx-gis")
+	}
+
+	return nil
+}
+
+func (c *Crawler) prepareLocation(ctx context.Context) error {
+	locationUrl := "https://www.instagram.com/explore/locations/" + url.PathEscape(c.config.Location) + "/"
+	sharedData, err := c.fetchSharedData(ctx, locationUrl)
+	if err != nil {
+		return err
+	}
+
+	c.location, err = sharedData.location()
+	if err != nil {
+		return err
+	}
+
+	c.rhxGis = sharedData.RhxGis
+	if c.rhxGis == "" {
+		return fmt.Errorf("couldn't find rhx-gis")
+	}
+
+	return nil
+}
+
+func (c *Crawler) fetchSharedData(ctx context.Context, pageUrl string) (*sharedDataJsonType, error) {
+	response, err := c.fetch(ctx, pageUrl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't fetch page: %s", pageUrl)
+	}
+
+	jsonString, err := extractSharedDataJsonString(response)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't parse sharedData json")
+	}
+
+	sharedData, err := decodeSharedData(jsonString)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid page json \"%s\"", jsonString)
+	}
+
+	return sharedData, nil
+}
+
+// 人気投稿はページングしない
+func (c *Crawler) handleTopPosts(ctx context.Context, m mediaJsonType, kind pageKind) {
+	m.PageInfo.HasNextPage = false
+	c.handleMedia(ctx, m, kind)
+}
+
+func (c *Crawler) handleHashtagPage(ctx context.Context, p page) error {
+	params := "{\"tag_name\":\"" + c.hashtag.Name + "\",\"first\":" + "12" + ",\"after\":\"" + p.cursor + "\"}"
+	pageJson := hashtagPageJsonType{}
+	if err := c.query(ctx, HashtagQueryHash, params, &pageJson); err != nil {
+		return err
+	}
+
+	c.handleMedia(ctx, pageJson.Data.Hashtag.Media, p.kind)
+
+	return nil
+}
+
+func (c *Crawler) handleLocationPage(ctx context.Context, p page) error {
+	params := "{\"id\":\"" + c.location.Id + "\",\"first\":" + "12" + ",\"after\":\"" + p.cursor + "\"}"
+	pageJson := locationPageJsonType{}
+	if err := c.query(ctx, LocationQueryHash, params, &pageJson); err != nil {
+		return err
+	}
+
+	c.handleMedia(ctx, pageJson.Data.Location.Media, p.kind)
+
+	return nil
+}
+
+type hashtagJsonType struct {
+	Name     string        `json:"name"`
+	Media    mediaJsonType `json:"edge_hashtag_to_media"`
+	TopPosts mediaJsonType `json:"edge_hashtag_to_top_posts"`
+}
+
+type locationJsonType struct {
+	Id       string        `json:"id"`
+	Name     string        `json:"name"`
+	Media    mediaJsonType `json:"edge_location_to_media"`
+	TopPosts mediaJsonType `json:"edge_location_to_top_posts"`
+}
+
+type hashtagPageJsonType struct {
+	Data struct {
+		Hashtag struct {
+			Media mediaJsonType `json:"edge_hashtag_to_media"`
+		} `json:"hashtag"`
+	} `json:"data"`
+}
+
+type locationPageJsonType struct {
+	Data struct {
+		Location struct {
+			Media mediaJsonType `json:"edge_location_to_media"`
+		} `json:"location"`
+	} `json:"data"`
+}
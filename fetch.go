@@ -1,18 +1,30 @@
 package crawler
 
 import (
+	"context"
 	"fmt"
+	"github.com/kouheiszk/ig-crawler/pkg/warc"
 	"github.com/moul/http2curl"
 	"github.com/pkg/errors"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"time"
 )
 
 const ErrorDelay = 30 * time.Second
+const ProxyRetryDelay = time.Second // 別のプロキシで再試行するまでの時間
 const RequestTimeout = 30 * time.Second
 
+type NotFoundError struct {
+	Url string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("not found \"%s\"", e.Url)
+}
+
 func fetch(url string) ([]byte, error) {
 	request, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -22,30 +34,45 @@ func fetch(url string) ([]byte, error) {
 }
 
 func fetchWithRequest(request *http.Request) ([]byte, error) {
+	return fetchWithClient(&http.Client{Timeout: RequestTimeout}, request, nil)
+}
+
+// リトライで待つ前に呼ばれ、待つ時間を返す。reasonは "throttled" か "connection"
+type retryFunc func(request *http.Request, reason string) time.Duration
+
+func fetchWithClient(client *http.Client, request *http.Request, onRetry retryFunc) ([]byte, error) {
 	command, _ := http2curl.GetCurlCommand(request)
 	log.Println(command)
 
-	client := &http.Client{
-		Timeout: RequestTimeout,
-	}
-
 	response, err := client.Do(request)
 	if err != nil {
+		// セッション切れやアーカイブの書き込み失敗はリトライしても回復しない
+		if urlErr, ok := err.(*url.Error); ok {
+			switch urlErr.Err.(type) {
+			case *SessionExpiredError, *warc.WriteError:
+				return nil, urlErr.Err
+			}
+		}
+
 		log.Print(errors.Wrap(err, "connection issue:"))
-		time.Sleep(ErrorDelay)
-		return fetchWithRequest(request)
+		if err := sleepWithContext(request.Context(), retryDelay(request, retryConnection, onRetry)); err != nil {
+			return nil, err
+		}
+		return fetchWithClient(client, rewindRequest(request), onRetry)
 	}
 	defer response.Body.Close()
 
 	if response.StatusCode == 429 {
 		log.Printf("throtteling \"%s\"", request.URL)
-		time.Sleep(ErrorDelay)
-		return fetchWithRequest(request)
+		if err := sleepWithContext(request.Context(), retryDelay(request, retryThrottled, onRetry)); err != nil {
+			return nil, err
+		}
+		return fetchWithClient(client, rewindRequest(request), onRetry)
 	}
 
 	if response.StatusCode == 404 {
 		log.Printf("not found \"%s\"", request.URL)
-		return nil, fmt.Errorf("not found \"%s\"", request.URL)
+		return nil, &NotFoundError{Url: request.URL.String()}
 	}
 
 	bytes, err := ioutil.ReadAll(response.Body)
@@ -55,3 +82,31 @@ func fetchWithRequest(request *http.Request) ([]byte, error) {
 
 	return bytes, nil
 }
+
+func retryDelay(request *http.Request, reason string, onRetry retryFunc) time.Duration {
+	if onRetry == nil {
+		return ErrorDelay
+	}
+
+	return onRetry(request, reason)
+}
+
+// POSTのリトライ時にはボディを読み直す
+func rewindRequest(request *http.Request) *http.Request {
+	if request.GetBody != nil {
+		if body, err := request.GetBody(); err == nil {
+			request.Body = body
+		}
+	}
+
+	return request
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
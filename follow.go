@@ -0,0 +1,269 @@
+package crawler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kouheiszk/ig-crawler/pkg/output"
+	"io/ioutil"
+	"os"
+	"strconv"
+)
+
+const FollowersQueryHash = "c76146de99bb02f6415203be841dd25a"
+const FollowingQueryHash = "d04b0a864b4b54837c0d870b0e77e076"
+const FollowsPerPage = 50
+
+type Account struct {
+	Id            string `json:"id"`
+	Username      string `json:"username"`
+	FullName      string `json:"full_name"`
+	IsVerified    bool   `json:"is_verified"`
+	ProfilePicUrl string `json:"profile_pic_url"`
+}
+
+type followKind string
+
+const (
+	followers followKind = "followers"
+	following followKind = "following"
+)
+
+type followPage struct {
+	kind   followKind
+	cursor string
+}
+
+// 取得済みのアカウントはCheckpoint+".jsonl"に追記し、チェックポイントにはカーソルと件数だけを持つ
+type followCheckpoint struct {
+	UserId string     `json:"user_id"`
+	Kind   followKind `json:"kind"`
+	Cursor string     `json:"cursor"`
+	Count  int        `json:"count"`
+	Done   bool       `json:"done"`
+}
+
+func FetchFollowers(config *Config) ([]Account, error) {
+	return FetchFollowersWithContext(context.Background(), config)
+}
+
+func FetchFollowersWithContext(ctx context.Context, config *Config) ([]Account, error) {
+	return fetchFollows(ctx, config, followers)
+}
+
+func FetchFollowing(config *Config) ([]Account, error) {
+	return FetchFollowingWithContext(context.Background(), config)
+}
+
+func FetchFollowingWithContext(ctx context.Context, config *Config) ([]Account, error) {
+	return fetchFollows(ctx, config, following)
+}
+
+func fetchFollows(ctx context.Context, config *Config, kind followKind) ([]Account, error) {
+	crawler := NewCrawler(config)
+	if crawler.config.Session == nil {
+		return nil, fmt.Errorf("%s list requires an authenticated session", kind)
+	}
+
+	if err := crawler.prepareConfig(ctx); err != nil {
+		return nil, err
+	}
+
+	checkpoint, accounts, err := crawler.loadFollowCheckpoint(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	crawler.store.accounts = accounts
+	if checkpoint.Done {
+		return crawler.store.accounts, nil
+	}
+
+	crawler.followPageChan <- followPage{kind, checkpoint.Cursor}
+	if err := crawler.runWorkers(ctx); err != nil {
+		return nil, err
+	}
+
+	return crawler.store.accounts, nil
+}
+
+func (c *Crawler) handleFollowPage(ctx context.Context, p followPage) error {
+	queryHash := FollowersQueryHash
+	if p.kind == following {
+		queryHash = FollowingQueryHash
+	}
+
+	params := "{\"id\":\"" + c.userId + "\",\"include_reel\":false,\"fetch_mutual\":false,\"first\":" + strconv.Itoa(FollowsPerPage) + ",\"after\":" + quote(p.cursor) + "}"
+	pageJson := followPageJsonType{}
+	if err := c.query(ctx, queryHash, params, &pageJson); err != nil {
+		return err
+	}
+
+	edges := pageJson.Data.User.EdgeFollowedBy
+	if p.kind == following {
+		edges = pageJson.Data.User.EdgeFollow
+	}
+
+	accounts := make([]Account, 0, len(edges.Edges))
+	for _, element := range edges.Edges {
+		accounts = append(accounts, Account{
+			Id:            element.Node.Id,
+			Username:      element.Node.Username,
+			FullName:      element.Node.FullName,
+			IsVerified:    element.Node.IsVerified,
+			ProfilePicUrl: element.Node.ProfilePicUrl,
+		})
+	}
+
+	checkpoint := c.store.addAccounts(c.userId, p.kind, accounts, edges.PageInfo)
+	if err := c.saveFollowCheckpoint(checkpoint, accounts); err != nil {
+		return err
+	}
+
+	if edges.PageInfo.HasNextPage {
+		c.followPageChan <- followPage{p.kind, edges.PageInfo.EndCursor}
+	}
+
+	return nil
+}
+
+func (s *ResourceStore) addAccounts(userId string, kind followKind, accounts []Account, pageInfo pageInfoJsonType) *followCheckpoint {
+	s.Lock()
+	defer s.Unlock()
+
+	s.accounts = append(s.accounts, accounts...)
+
+	return &followCheckpoint{
+		UserId: userId,
+		Kind:   kind,
+		Cursor: pageInfo.EndCursor,
+		Count:  len(s.accounts),
+		Done:   !pageInfo.HasNextPage,
+	}
+}
+
+func (c *Crawler) loadFollowCheckpoint(kind followKind) (*followCheckpoint, []Account, error) {
+	checkpoint := &followCheckpoint{UserId: c.userId, Kind: kind}
+	if c.config.Checkpoint == "" {
+		return checkpoint, nil, nil
+	}
+
+	bytes, err := ioutil.ReadFile(c.config.Checkpoint)
+	if os.IsNotExist(err) {
+		// 最初のチェックポイントを書く前に中断した場合のアカウントは捨てる
+		if err = os.Remove(followAccountsPath(c.config.Checkpoint)); err != nil && !os.IsNotExist(err) {
+			return nil, nil, err
+		}
+		return checkpoint, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = json.Unmarshal(bytes, checkpoint); err != nil {
+		return nil, nil, fmt.Errorf("invalid checkpoint \"%s\": %v", c.config.Checkpoint, err)
+	}
+
+	if checkpoint.UserId != c.userId || checkpoint.Kind != kind {
+		return nil, nil, fmt.Errorf("checkpoint \"%s\" is for %s of user %s", c.config.Checkpoint, checkpoint.Kind, checkpoint.UserId)
+	}
+
+	accounts, err := loadFollowAccounts(followAccountsPath(c.config.Checkpoint), checkpoint.Count)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return checkpoint, accounts, nil
+}
+
+// チェックポイントの件数より後ろは、保存前に中断したページなので切り捨てる
+func loadFollowAccounts(path string, count int) ([]Account, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	accounts := make([]Account, 0, count)
+	var offset int64
+	for len(accounts) < count {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return nil, fmt.Errorf("\"%s\" has only %d of %d accounts", path, len(accounts), count)
+		}
+
+		account := Account{}
+		if err = json.Unmarshal(line, &account); err != nil {
+			return nil, fmt.Errorf("invalid account in \"%s\": %v", path, err)
+		}
+		accounts = append(accounts, account)
+		offset += int64(len(line))
+	}
+
+	return accounts, file.Truncate(offset)
+}
+
+// アカウントを追記してから、カーソルと件数だけのチェックポイントを置き換える
+func (c *Crawler) saveFollowCheckpoint(checkpoint *followCheckpoint, accounts []Account) error {
+	if c.config.Checkpoint == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(followAccountsPath(c.config.Checkpoint), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	for _, account := range accounts {
+		if err = encoder.Encode(account); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	bytes, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	return output.WriteFileAtomic(c.config.Checkpoint, bytes, 0644)
+}
+
+func followAccountsPath(checkpoint string) string {
+	return checkpoint + ".jsonl"
+}
+
+type followEdgesJsonType struct {
+	Count    int              `json:"count"`
+	PageInfo pageInfoJsonType `json:"page_info"`
+	Edges    []struct {
+		Node struct {
+			Id            string `json:"id"`
+			Username      string `json:"username"`
+			FullName      string `json:"full_name"`
+			IsVerified    bool   `json:"is_verified"`
+			ProfilePicUrl string `json:"profile_pic_url"`
+		} `json:"node"`
+	} `json:"edges"`
+}
+
+type followPageJsonType struct {
+	Data struct {
+		User struct {
+			EdgeFollowedBy followEdgesJsonType `json:"edge_followed_by"`
+			EdgeFollow     followEdgesJsonType `json:"edge_follow"`
+		} `json:"user"`
+	} `json:"data"`
+}
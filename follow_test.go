@@ -0,0 +1,52 @@
+package crawler
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFollowCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "follow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	crawler := NewCrawler(&Config{Checkpoint: filepath.Join(dir, "followers.json")})
+	crawler.userId = "1"
+
+	pages := [][]Account{{{Id: "a"}, {Id: "b"}}, {{Id: "c"}}}
+	for _, accounts := range pages {
+		checkpoint := crawler.store.addAccounts("1", followers, accounts, pageInfoJsonType{EndCursor: "next", HasNextPage: true})
+		if err := crawler.saveFollowCheckpoint(checkpoint, accounts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// チェックポイントを書く前に中断したページ
+	file, err := os.OpenFile(followAccountsPath(crawler.config.Checkpoint), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("{\"id\":\"d\"}\n")
+	file.Close()
+
+	checkpoint, accounts, err := crawler.loadFollowCheckpoint(followers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.Count != 3 || checkpoint.Cursor != "next" || len(accounts) != 3 || accounts[2].Id != "c" {
+		t.Errorf("unexpected checkpoint %+v, accounts %v", checkpoint, accounts)
+	}
+
+	_, accounts, err = crawler.loadFollowCheckpoint(followers)
+	if err != nil || len(accounts) != 3 {
+		t.Errorf("unexpected accounts after truncation %v, %v", accounts, err)
+	}
+
+	if _, _, err = crawler.loadFollowCheckpoint(following); err == nil {
+		t.Error("expected error for checkpoint of another kind")
+	}
+}
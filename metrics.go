@@ -0,0 +1,281 @@
+package crawler
+
+import (
+	"fmt"
+	"github.com/kouheiszk/ig-crawler/pkg/metrics"
+	"github.com/kouheiszk/ig-crawler/pkg/proxy"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	retryThrottled  = "throttled"
+	retryConnection = "connection"
+)
+
+// 複数のクロールで共有でき、サーバーモードでは /metrics で公開する
+type Metrics struct {
+	*metrics.Registry
+
+	requests   *metrics.CounterVec
+	duration   *metrics.HistogramVec
+	bytes      *metrics.CounterVec
+	retries    *metrics.CounterVec
+	throttled  *metrics.CounterVec
+	crawlDelay *metrics.GaugeVec
+	resources  *metrics.CounterVec
+	proxies    *metrics.CounterVec
+	coolDowns  *metrics.CounterVec
+
+	mutex    sync.Mutex
+	crawlers map[*Crawler]struct{}
+}
+
+func NewMetrics() *Metrics {
+	registry := metrics.NewRegistry()
+	m := &Metrics{
+		Registry:   registry,
+		requests:   registry.Counter("ig_crawler_requests_total", "HTTP requests by class and status code.", "class", "code"),
+		duration:   registry.Histogram("ig_crawler_request_duration_seconds", "Time until response headers by request class.", nil, "class"),
+		bytes:      registry.Counter("ig_crawler_response_bytes_total", "Response body bytes read by request class.", "class"),
+		retries:    registry.Counter("ig_crawler_retries_total", "Requests retried by class and reason.", "class", "reason"),
+		throttled:  registry.Counter("ig_crawler_throttled_total", "Responses with status 429 by request class.", "class"),
+		crawlDelay: registry.Gauge("ig_crawler_crawl_delay_seconds", "Current delay between GraphQL requests by target.", "target"),
+		resources:  registry.Counter("ig_crawler_resources_total", "Resources emitted by media and source.", "media", "source"),
+		proxies:    registry.Counter("ig_crawler_proxy_requests_total", "Requests sent through each proxy by result.", "proxy", "result"),
+		coolDowns:  registry.Counter("ig_crawler_proxy_cool_downs_total", "Times each proxy was taken out of rotation.", "proxy"),
+		crawlers:   map[*Crawler]struct{}{},
+	}
+	registry.GaugeFunc("ig_crawler_queue_length", "Items waiting in each work queue.", m.collectQueues, "queue")
+
+	return m
+}
+
+func (m *Metrics) track(c *Crawler) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.crawlers[c] = struct{}{}
+}
+
+func (m *Metrics) untrack(c *Crawler) {
+	m.mutex.Lock()
+	delete(m.crawlers, c)
+	m.mutex.Unlock()
+
+	m.crawlDelay.Delete(c.target())
+}
+
+// チャネルの長さは読むだけならロックなしで取れる
+func (m *Metrics) collectQueues(set func(float64, ...string)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for c := range m.crawlers {
+		set(float64(len(c.pageChan)), "page")
+		set(float64(len(c.resourceChan)), "resource")
+		set(float64(len(c.galleryPageChan)), "gallery_page")
+		set(float64(len(c.videoPageChan)), "video_page")
+		set(float64(len(c.reelChan)), "reel")
+		set(float64(len(c.commentPageChan)), "comment_page")
+		set(float64(len(c.followPageChan)), "follow_page")
+	}
+}
+
+func (m *Metrics) observeRetry(request *http.Request, reason string) {
+	m.retries.Inc(requestClass(request), reason)
+}
+
+func (m *Metrics) observeProxy(event proxy.Event) {
+	m.proxies.Inc(event.Proxy, string(event.Result))
+	if event.CoolDown > 0 {
+		m.coolDowns.Inc(event.Proxy)
+	}
+}
+
+func (m *Metrics) observeResource(r Resource) {
+	media := "image"
+	if r.IsVideo {
+		media = "video"
+	}
+
+	source := r.Tab
+	switch {
+	case r.HighlightId != "":
+		source = "highlight"
+	case r.ExpiresAt != 0:
+		source = "story"
+	case source == "":
+		source = "posts"
+	}
+
+	m.resources.Inc(media, source)
+}
+
+// CLIの終了時に出す要約
+func (m *Metrics) WriteSummary(w io.Writer) error {
+	families := map[string]metrics.Family{}
+	for _, f := range m.Gather() {
+		families[f.Name] = f
+	}
+
+	lines := []struct {
+		title  string
+		family string
+		label  string
+		format func(float64) string
+		always bool
+	}{
+		{"requests", "ig_crawler_requests_total", "class", formatCount, true},
+		{"status codes", "ig_crawler_requests_total", "code", formatCount, true},
+		{"retries", "ig_crawler_retries_total", "reason", formatCount, true},
+		{"throttled", "ig_crawler_throttled_total", "class", formatCount, true},
+		{"bytes", "ig_crawler_response_bytes_total", "class", formatBytes, true},
+		{"resources", "ig_crawler_resources_total", "media", formatCount, true},
+		{"proxies", "ig_crawler_proxy_requests_total", "proxy", formatCount, false},
+		{"proxy results", "ig_crawler_proxy_requests_total", "result", formatCount, false},
+		{"proxy cool-downs", "ig_crawler_proxy_cool_downs_total", "proxy", formatCount, false},
+	}
+
+	for _, line := range lines {
+		// プロキシを使っていなければ出さない
+		if !line.always && len(families[line.family].Samples) == 0 {
+			continue
+		}
+
+		total, by := sumBy(families[line.family], line.label)
+		parts := make([]string, 0, len(by))
+		for _, key := range sortedKeys(by) {
+			parts = append(parts, key+" "+line.format(by[key]))
+		}
+
+		text := fmt.Sprintf("%s: %s", line.title, line.format(total))
+		if len(parts) > 0 {
+			text += " (" + strings.Join(parts, ", ") + ")"
+		}
+		if _, err := fmt.Fprintln(w, text); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sumBy(f metrics.Family, label string) (float64, map[string]float64) {
+	var total float64
+	by := map[string]float64{}
+	for _, sample := range f.Samples {
+		total += sample.Value
+		for _, l := range sample.Labels {
+			if l.Name == label {
+				by[l.Value] += sample.Value
+			}
+		}
+	}
+
+	return total, by
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func formatCount(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func formatBytes(f float64) string {
+	units := []string{"B", "KB", "MB", "GB"}
+	i := 0
+	for f >= 1024 && i < len(units)-1 {
+		f /= 1024
+		i++
+	}
+
+	return strconv.FormatFloat(f, 'f', 1, 64) + " " + units[i]
+}
+
+// リクエストの種類はURLから判断する
+func requestClass(request *http.Request) string {
+	path := request.URL.Path
+	switch {
+	case !isInstagramHost(request.URL.Hostname()):
+		return "media"
+	case isGraphqlRequest(request):
+		return "graphql"
+	case strings.HasSuffix(path, ".js"):
+		return "script"
+	case strings.HasPrefix(path, "/api/"):
+		return "api"
+	case strings.HasPrefix(path, "/p/") || strings.HasPrefix(path, "/tv/") || strings.HasPrefix(path, "/reel/"):
+		return "post"
+	default:
+		return "profile"
+	}
+}
+
+type metricsTransport struct {
+	metrics *Metrics
+	base    http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	class := requestClass(request)
+	start := time.Now()
+	response, err := base.RoundTrip(request)
+	t.metrics.duration.Observe(time.Since(start).Seconds(), class)
+	if err != nil {
+		t.metrics.requests.Inc(class, "error")
+		return nil, err
+	}
+
+	t.metrics.requests.Inc(class, strconv.Itoa(response.StatusCode))
+	if response.StatusCode == http.StatusTooManyRequests {
+		t.metrics.throttled.Inc(class)
+	}
+	response.Body = &countingBody{ReadCloser: response.Body, counter: t.metrics.bytes, class: class}
+
+	return response, nil
+}
+
+type countingBody struct {
+	io.ReadCloser
+	counter *metrics.CounterVec
+	class   string
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.counter.Add(float64(n), b.class)
+	}
+
+	return n, err
+}
+
+func (c *Crawler) target() string {
+	switch {
+	case c.config.Hashtag != "":
+		return "#" + c.config.Hashtag
+	case c.config.Location != "":
+		return "location/" + c.config.Location
+	default:
+		return c.config.Username
+	}
+}
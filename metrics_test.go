@@ -0,0 +1,140 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"github.com/kouheiszk/ig-crawler/pkg/proxy"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestClass(t *testing.T) {
+	cases := []struct {
+		url   string
+		class string
+	}{
+		{"https://www.instagram.com/kouheiszk/", "profile"},
+		{"https://www.instagram.com/graphql/query/?query_hash=x", "graphql"},
+		{"https://www.instagram.com/static/bundles/ProfilePageContainer.js/abc.js", "script"},
+		{"https://www.instagram.com/p/B1234567890/", "post"},
+		{"https://i.instagram.com/api/v1/users/1/info/", "api"},
+		{"https://scontent.cdninstagram.com/v/t51.jpg", "media"},
+	}
+
+	for _, c := range cases {
+		request, _ := http.NewRequest("GET", c.url, nil)
+		if class := requestClass(request); class != c.class {
+			t.Errorf("%s: expected %s, got %s", c.url, c.class, class)
+		}
+	}
+}
+
+func TestMetricsTransport(t *testing.T) {
+	m := NewMetrics()
+	crawler := NewCrawler(&Config{Username: "kouheiszk", Metrics: m})
+	crawler.client.Transport = &metricsTransport{metrics: m, base: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader("hello")),
+			Header:     http.Header{},
+			Request:    request,
+		}, nil
+	})}
+
+	if _, err := crawler.fetch(context.Background(), "https://www.instagram.com/kouheiszk/"); err != nil {
+		t.Fatal(err)
+	}
+	crawler.handleResource(context.Background(), Resource{Url: "https://example.com/a.mp4", IsVideo: true, Tab: "reels"})
+	crawler.handleResource(context.Background(), Resource{Url: "https://example.com/b.jpg", HighlightId: "1"})
+
+	if v := m.requests.Value("profile", "200"); v != 1 {
+		t.Errorf("unexpected requests %v", v)
+	}
+	if v := m.bytes.Value("profile"); v != 5 {
+		t.Errorf("unexpected bytes %v", v)
+	}
+	if m.resources.Value("video", "reels") != 1 || m.resources.Value("image", "highlight") != 1 {
+		t.Error("resources are not counted by type")
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := m.WriteSummary(buffer); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"requests: 1 (profile 1)", "status codes: 1 (200 1)", "bytes: 5.0 B (profile 5.0 B)", "resources: 2 (image 1, video 1)"} {
+		if !strings.Contains(buffer.String(), line) {
+			t.Errorf("summary is missing %q\n%s", line, buffer.String())
+		}
+	}
+	if strings.Contains(buffer.String(), "prox") {
+		t.Errorf("summary must not mention proxies when they're not used\n%s", buffer.String())
+	}
+}
+
+func TestProxyRetry(t *testing.T) {
+	throttled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer throttled.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	}))
+	defer healthy.Close()
+
+	pool, err := proxy.New([]string{throttled.URL, healthy.URL}, proxy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var delays []time.Duration
+	crawler := NewCrawler(&Config{Username: "kouheiszk", Proxies: pool, Progress: ProgressReporterFunc(func(event ProgressEvent) {
+		if event.Kind == RetryScheduled {
+			delays = append(delays, event.Delay)
+		}
+	})})
+
+	// 429を返したプロキシを待たずに、次のプロキシで再試行する
+	start := time.Now()
+	response, err := crawler.fetch(context.Background(), "http://www.instagram.com/kouheiszk/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(response) != "hello" || len(delays) != 1 || delays[0] != ProxyRetryDelay || time.Since(start) > ErrorDelay/2 {
+		t.Errorf("unexpected retry %q after %v with delays %v", response, time.Since(start), delays)
+	}
+}
+
+func TestProxyMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	}))
+	defer server.Close()
+
+	pool, err := proxy.New([]string{server.URL}, proxy.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMetrics()
+	crawler := NewCrawler(&Config{Username: "kouheiszk", Metrics: m, Proxies: pool})
+
+	if _, err := crawler.fetch(context.Background(), "http://www.instagram.com/kouheiszk/"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := m.proxies.Value(server.URL, "succeeded"); v != 1 {
+		t.Errorf("unexpected proxy requests %v", v)
+	}
+	if v := m.bytes.Value("profile"); v != 5 {
+		t.Errorf("proxied responses must be counted, got %v bytes", v)
+	}
+
+	buffer := &bytes.Buffer{}
+	m.WriteSummary(buffer)
+	if line := "proxies: 1 (" + server.URL + " 1)"; !strings.Contains(buffer.String(), line) {
+		t.Errorf("summary is missing %q\n%s", line, buffer.String())
+	}
+}
@@ -0,0 +1,268 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/kouheiszk/ig-crawler"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const TitleLength = 80
+
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Author      string
+	Updated     time.Time
+	Entries     []Entry
+}
+
+type Entry struct {
+	Id         string
+	Title      string
+	Link       string
+	Content    string // HTML
+	Published  time.Time
+	Enclosures []Enclosure
+}
+
+type Enclosure struct {
+	Url  string
+	Type string
+}
+
+func FromPosts(profile *crawler.Profile, posts []crawler.Post) *Feed {
+	feed := &Feed{
+		Title:       profile.FullName,
+		Link:        "https://www.instagram.com/" + profile.Username + "/",
+		Description: profile.Biography,
+		Author:      profile.Username,
+	}
+	if feed.Title == "" {
+		feed.Title = profile.Username
+	}
+
+	for _, post := range posts {
+		entry := Entry{
+			Id:        post.Url,
+			Title:     title(post),
+			Link:      post.Url,
+			Published: time.Unix(int64(post.Timestamp), 0).UTC(),
+		}
+
+		entry.Enclosures = enclosures(post)
+		entry.Content = content(post.Caption, entry.Enclosures)
+
+		if entry.Published.After(feed.Updated) {
+			feed.Updated = entry.Published
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Now().UTC()
+	}
+
+	return feed
+}
+
+func (f *Feed) Atom() ([]byte, error) {
+	atom := atomFeed{
+		Xmlns:    "http://www.w3.org/2005/Atom",
+		Id:       f.Link,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.Format(time.RFC3339),
+		Links:    []atomLink{{Rel: "alternate", Href: f.Link}},
+		Author:   atomAuthor{Name: f.Author, Uri: f.Link},
+	}
+
+	for _, entry := range f.Entries {
+		published := entry.Published.Format(time.RFC3339)
+		atomEntry := atomEntry{
+			Id:        entry.Id,
+			Title:     entry.Title,
+			Links:     []atomLink{{Rel: "alternate", Href: entry.Link}},
+			Published: published,
+			Updated:   published,
+			Content:   atomContent{Type: "html", Body: entry.Content},
+		}
+		for _, enclosure := range entry.Enclosures {
+			atomEntry.Links = append(atomEntry.Links, atomLink{Rel: "enclosure", Href: enclosure.Url, Type: enclosure.Type})
+		}
+		atom.Entries = append(atom.Entries, atomEntry)
+	}
+
+	return marshal(atom)
+}
+
+// RSS 2.0では1アイテムにつき添付は1つまでなので、最初のメディアだけを添付する
+func (f *Feed) RSS() ([]byte, error) {
+	rss := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Description,
+			LastBuildDate: f.Updated.Format(time.RFC1123Z),
+		},
+	}
+
+	for _, entry := range f.Entries {
+		item := rssItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Guid:        rssGuid{IsPermaLink: true, Value: entry.Id},
+			Description: entry.Content,
+			PubDate:     entry.Published.Format(time.RFC1123Z),
+		}
+		if len(entry.Enclosures) > 0 {
+			item.Enclosure = &rssEnclosure{Url: entry.Enclosures[0].Url, Type: entry.Enclosures[0].Type, Length: 0}
+		}
+		rss.Channel.Items = append(rss.Channel.Items, item)
+	}
+
+	return marshal(rss)
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	bytes, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(bytes, '\n')...), nil
+}
+
+func title(post crawler.Post) string {
+	line := strings.TrimSpace(strings.SplitN(post.Caption, "\n", 2)[0])
+	if line == "" {
+		return post.Shortcode
+	}
+
+	if utf8.RuneCountInString(line) > TitleLength {
+		return string([]rune(line)[:TitleLength]) + "…"
+	}
+
+	return line
+}
+
+func enclosures(post crawler.Post) []Enclosure {
+	if len(post.Children) > 0 {
+		var enclosures []Enclosure
+		for _, child := range post.Children {
+			enclosures = append(enclosures, enclosure(child.MediaUrl(), child.IsVideo))
+		}
+		return enclosures
+	}
+
+	if post.IsVideo && post.VideoUrl != "" {
+		return []Enclosure{enclosure(post.VideoUrl, true)}
+	}
+
+	if post.DisplayUrl != "" {
+		return []Enclosure{enclosure(post.DisplayUrl, false)}
+	}
+
+	return nil
+}
+
+func enclosure(url string, isVideo bool) Enclosure {
+	if isVideo {
+		return Enclosure{Url: url, Type: "video/mp4"}
+	}
+
+	return Enclosure{Url: url, Type: "image/jpeg"}
+}
+
+func content(caption string, enclosures []Enclosure) string {
+	var b strings.Builder
+	if caption != "" {
+		fmt.Fprintf(&b, "<p>%s</p>", strings.Replace(html.EscapeString(caption), "\n", "<br>", -1))
+	}
+
+	for _, enclosure := range enclosures {
+		if enclosure.Type == "video/mp4" {
+			fmt.Fprintf(&b, `<video src="%s" controls></video>`, html.EscapeString(enclosure.Url))
+		} else {
+			fmt.Fprintf(&b, `<img src="%s">`, html.EscapeString(enclosure.Url))
+		}
+	}
+
+	return b.String()
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Id       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomAuthor  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	Uri  string `xml:"uri,omitempty"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Id        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Links     []atomLink  `xml:"link"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Guid        rssGuid       `xml:"guid"`
+	Description string        `xml:"description"`
+	PubDate     string        `xml:"pubDate"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	Url    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int    `xml:"length,attr"`
+}
@@ -0,0 +1,69 @@
+package feed
+
+import (
+	"encoding/xml"
+	"github.com/kouheiszk/ig-crawler"
+	"strings"
+	"testing"
+)
+
+var profile = &crawler.Profile{Username: "kouheiszk", FullName: "Kouhei", Biography: "bio"}
+
+var posts = []crawler.Post{
+	{
+		Shortcode: "BrEWmLxnL0T",
+		Url:       "https://www.instagram.com/p/BrEWmLxnL0T/",
+		Caption:   "first line <b>\nsecond line",
+		Timestamp: 1544000000,
+		Children: []crawler.PostChild{
+			{DisplayUrl: "https://example.com/a.jpg"},
+			{IsVideo: true, VideoUrl: "https://example.com/b.mp4"},
+		},
+	},
+}
+
+func TestAtom(t *testing.T) {
+	bytes, err := FromPosts(profile, posts).Atom()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	atom := atomFeed{}
+	if err = xml.Unmarshal(bytes, &atom); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(atom.Entries) != 1 {
+		t.Fatalf("unexpected entries %v", atom.Entries)
+	}
+	entry := atom.Entries[0]
+	if entry.Id != posts[0].Url || entry.Title != "first line <b>" || entry.Published != "2018-12-05T08:53:20Z" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if len(entry.Links) != 3 || entry.Links[2].Type != "video/mp4" {
+		t.Errorf("unexpected links %+v", entry.Links)
+	}
+	if !strings.Contains(entry.Content.Body, "first line &lt;b&gt;<br>second line") {
+		t.Errorf("unexpected content %s", entry.Content.Body)
+	}
+}
+
+func TestRSS(t *testing.T) {
+	bytes, err := FromPosts(profile, posts).RSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rss := rssFeed{}
+	if err = xml.Unmarshal(bytes, &rss); err != nil {
+		t.Fatal(err)
+	}
+
+	item := rss.Channel.Items[0]
+	if rss.Version != "2.0" || item.Guid.Value != posts[0].Url || item.PubDate != "Wed, 05 Dec 2018 08:53:20 +0000" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if item.Enclosure == nil || item.Enclosure.Url != "https://example.com/a.jpg" {
+		t.Errorf("unexpected enclosure %+v", item.Enclosure)
+	}
+}
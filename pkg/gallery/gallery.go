@@ -0,0 +1,206 @@
+package gallery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kouheiszk/ig-crawler"
+	"github.com/kouheiszk/ig-crawler/pkg/output"
+	"github.com/pkg/errors"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+const MediaDir = "media"
+const PostDir = "p"
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"date": func(timestamp int32) string {
+		return time.Unix(int64(timestamp), 0).UTC().Format("2006-01-02 15:04")
+	},
+}).Parse(layoutTemplate + indexTemplate + postTemplate))
+
+type Gallery struct {
+	Dir    string
+	Client *http.Client
+}
+
+type Media struct {
+	Path    string // サイトのルートからの相対パス
+	Poster  string
+	IsVideo bool
+}
+
+type Item struct {
+	crawler.Post
+	Media []Media
+}
+
+// clientにはcrawler.NewClientで作ったものを渡すと、クロールと同じプロキシやヘッダでダウンロードしてアーカイブにも残る
+func New(dir string, client *http.Client) *Gallery {
+	return &Gallery{Dir: dir, Client: client}
+}
+
+// メディアをダウンロードしてページを描画し、内容が変わって書き換えたページを返す
+func (g *Gallery) Generate(ctx context.Context, profile *crawler.Profile, posts []crawler.Post) ([]string, error) {
+	var items []Item
+	for _, post := range posts {
+		item, err := g.download(ctx, post)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	var written []string
+	write := func(name string, templateName string, data interface{}) error {
+		buffer := bytes.Buffer{}
+		if err := templates.ExecuteTemplate(&buffer, templateName, data); err != nil {
+			return err
+		}
+
+		changed, err := writeIfChanged(filepath.Join(g.Dir, name), buffer.Bytes())
+		if err != nil {
+			return err
+		}
+		if changed {
+			written = append(written, name)
+		}
+
+		return nil
+	}
+
+	if err := write("index.html", "index", map[string]interface{}{"Profile": profile, "Items": items, "Root": ""}); err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		name := path.Join(PostDir, item.Shortcode+".html")
+		if err := write(name, "post", map[string]interface{}{"Profile": profile, "Item": item, "Root": "../"}); err != nil {
+			return nil, err
+		}
+	}
+
+	return written, nil
+}
+
+func (g *Gallery) download(ctx context.Context, post crawler.Post) (Item, error) {
+	item := Item{Post: post}
+
+	type source struct {
+		url     string
+		poster  string
+		isVideo bool
+	}
+	var sources []source
+	if len(post.Children) > 0 {
+		for _, child := range post.Children {
+			s := source{url: child.MediaUrl(), isVideo: child.IsVideo}
+			if child.IsVideo {
+				s.poster = child.DisplayUrl
+			}
+			sources = append(sources, s)
+		}
+	} else if post.IsVideo && post.VideoUrl != "" {
+		sources = append(sources, source{post.VideoUrl, post.DisplayUrl, true})
+	} else {
+		sources = append(sources, source{post.DisplayUrl, "", false})
+	}
+
+	for i, s := range sources {
+		if s.url == "" {
+			continue
+		}
+
+		media := Media{IsVideo: s.isVideo}
+		name := fmt.Sprintf("%s_%d", post.Shortcode, i)
+
+		var err error
+		media.Path, err = g.fetch(ctx, s.url, name)
+		if err != nil {
+			return item, err
+		}
+		if s.poster != "" {
+			media.Poster, err = g.fetch(ctx, s.poster, name+"_poster")
+			if err != nil {
+				return item, err
+			}
+		}
+
+		item.Media = append(item.Media, media)
+	}
+
+	return item, nil
+}
+
+// ダウンロード済みのメディアは取り直さない
+func (g *Gallery) fetch(ctx context.Context, rawUrl string, name string) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid media url \"%s\"", rawUrl)
+	}
+
+	ext := path.Ext(u.Path)
+	if ext == "" {
+		ext = ".jpg"
+	}
+	relative := path.Join(MediaDir, name+ext)
+	file := filepath.Join(g.Dir, filepath.FromSlash(relative))
+
+	if _, err = os.Stat(file); err == nil {
+		return relative, nil
+	}
+
+	request, err := http.NewRequest("GET", rawUrl, nil)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := g.Client.Do(request.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("couldn't download \"%s\": %s", rawUrl, response.Status)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", err
+	}
+
+	tmp, err := output.CreateAtomic(file)
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(tmp, response.Body); err != nil {
+		tmp.Abort()
+		return "", err
+	}
+
+	return relative, tmp.Close()
+}
+
+func writeIfChanged(file string, content []byte) (bool, error) {
+	current, err := ioutil.ReadFile(file)
+	if err == nil && bytes.Equal(current, content) {
+		return false, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return false, err
+	}
+
+	return true, output.WriteFileAtomic(file, content, 0644)
+}
@@ -0,0 +1,77 @@
+package gallery
+
+import (
+	"context"
+	"github.com/kouheiszk/ig-crawler"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	config := crawler.NewConfig()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// クロールと同じブラウザとして取得する
+		if r.Header.Get("User-Agent") != config.UserAgent {
+			t.Errorf("unexpected user agent %s", r.Header.Get("User-Agent"))
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "gallery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	profile := &crawler.Profile{Username: "kouheiszk"}
+	posts := []crawler.Post{
+		{Shortcode: "a", Caption: "<script>", DisplayUrl: server.URL + "/a.jpg"},
+		{Shortcode: "b", IsVideo: true, DisplayUrl: server.URL + "/b.jpg", VideoUrl: server.URL + "/b.mp4"},
+		{Shortcode: "c", Children: []crawler.PostChild{
+			{DisplayUrl: server.URL + "/c0.jpg"},
+			{IsVideo: true, DisplayUrl: server.URL + "/c1.jpg", VideoUrl: server.URL + "/c1.mp4"},
+		}},
+	}
+
+	g := New(dir, crawler.NewClient(config))
+	written, err := g.Generate(context.Background(), profile, posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 4 || requests != 6 {
+		t.Errorf("unexpected written pages %v, requests %d", written, requests)
+	}
+
+	page, err := ioutil.ReadFile(filepath.Join(dir, "p", "b.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), `<video src="../media/b_0.mp4" poster="../media/b_0_poster.jpg"`) {
+		t.Errorf("unexpected post page %s", page)
+	}
+
+	index, err := ioutil.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(index), "<script>") {
+		t.Error("caption is not escaped")
+	}
+
+	posts[0].Caption = "changed"
+	written, err = g.Generate(context.Background(), profile, posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 2 || requests != 6 {
+		t.Errorf("unexpected written pages %v, requests %d", written, requests)
+	}
+}
@@ -0,0 +1,60 @@
+package gallery
+
+const layoutTemplate = `
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.}}</title>
+<style>
+body { margin: 0 auto; max-width: 960px; padding: 16px; font-family: -apple-system, BlinkMacSystemFont, sans-serif; color: #262626; }
+a { color: inherit; }
+header { margin-bottom: 24px; }
+.grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(200px, 1fr)); grid-gap: 8px; }
+.grid a { display: block; position: relative; padding-top: 100%; background: #efefef; }
+.grid img { position: absolute; top: 0; left: 0; width: 100%; height: 100%; object-fit: cover; }
+.grid .badge { position: absolute; top: 8px; right: 8px; color: #fff; text-shadow: 0 0 4px #000; }
+.carousel { display: flex; overflow-x: auto; scroll-snap-type: x mandatory; }
+.carousel > * { flex: 0 0 100%; max-width: 100%; scroll-snap-align: start; }
+.caption { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+{{end}}
+{{define "footer"}}</body>
+</html>
+{{end}}
+`
+
+const indexTemplate = `
+{{define "index"}}{{template "header" .Profile.Username}}<header>
+<h1>{{.Profile.Username}}</h1>
+{{if .Profile.FullName}}<p><strong>{{.Profile.FullName}}</strong></p>{{end}}
+{{if .Profile.Biography}}<p class="caption">{{.Profile.Biography}}</p>{{end}}
+<p>{{.Profile.PostCount}} posts · {{.Profile.FollowerCount}} followers · {{.Profile.FollowingCount}} following</p>
+</header>
+<main class="grid">
+{{range .Items}}{{$item := .}}{{if .Media}}{{with index .Media 0}}<a href="{{$.Root}}p/{{$item.Shortcode}}.html" title="{{$item.Caption}}">
+<img src="{{$.Root}}{{if .Poster}}{{.Poster}}{{else}}{{.Path}}{{end}}" loading="lazy" alt="">
+{{if gt (len $item.Media) 1}}<span class="badge">&#x2750;</span>{{else if .IsVideo}}<span class="badge">&#x25B6;</span>{{end}}
+</a>
+{{end}}{{end}}{{end}}</main>
+{{template "footer"}}{{end}}
+`
+
+const postTemplate = `
+{{define "post"}}{{template "header" .Item.Shortcode}}<header>
+<p><a href="{{.Root}}index.html">&larr; {{.Profile.Username}}</a></p>
+</header>
+<main>
+<div class="carousel">
+{{range .Item.Media}}{{if .IsVideo}}<video src="{{$.Root}}{{.Path}}"{{if .Poster}} poster="{{$.Root}}{{.Poster}}"{{end}} controls playsinline></video>
+{{else}}<img src="{{$.Root}}{{.Path}}" alt="">
+{{end}}{{end}}</div>
+<p class="caption">{{.Item.Caption}}</p>
+<p><time>{{date .Item.Timestamp}}</time> · {{.Item.LikeCount}} likes · {{.Item.CommentCount}} comments</p>
+<p><a href="{{.Item.Url}}">{{.Item.Url}}</a></p>
+</main>
+{{template "footer"}}{{end}}
+`
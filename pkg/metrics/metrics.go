@@ -0,0 +1,324 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Type string
+
+const (
+	CounterType   Type = "counter"
+	GaugeType     Type = "gauge"
+	HistogramType Type = "histogram"
+)
+
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// 外部ライブラリに頼らずPrometheusのテキスト形式で出力できる最小限のレジストリ
+type Registry struct {
+	mutex    sync.Mutex
+	families []family
+}
+
+type family interface {
+	gather() Family
+}
+
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+type Sample struct {
+	Name   string // ヒストグラムでは _bucket、_sum、_count が付く
+	Labels []Label
+	Value  float64
+}
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(f family) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.families = append(r.families, f)
+}
+
+// 登録した順にメトリクスの現在値を返す
+func (r *Registry) Gather() []Family {
+	r.mutex.Lock()
+	families := append([]family{}, r.families...)
+	r.mutex.Unlock()
+
+	gathered := make([]Family, 0, len(families))
+	for _, f := range families {
+		gathered = append(gathered, f.gather())
+	}
+
+	return gathered
+}
+
+type vec struct {
+	name   string
+	help   string
+	labels []string
+
+	mutex  sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	values  []string
+	value   float64
+	buckets []uint64 // ヒストグラムのみ、累積しない
+	count   uint64
+}
+
+func newVec(name string, help string, labels []string) vec {
+	return vec{name: name, help: help, labels: labels, series: map[string]*series{}}
+}
+
+func (v *vec) with(values []string) *series {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{values: append([]string{}, values...)}
+		v.series[key] = s
+	}
+
+	return s
+}
+
+func (v *vec) delete(values []string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	delete(v.series, strings.Join(values, "\xff"))
+}
+
+// ラベルの値で並べて出力を安定させる
+func (v *vec) sorted() []*series {
+	list := make([]*series, 0, len(v.series))
+	for _, s := range v.series {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.Join(list[i].values, "\xff") < strings.Join(list[j].values, "\xff")
+	})
+
+	return list
+}
+
+func (v *vec) labelPairs(values []string) []Label {
+	labels := make([]Label, len(values))
+	for i, value := range values {
+		labels[i] = Label{Name: v.labels[i], Value: value}
+	}
+
+	return labels
+}
+
+type CounterVec struct {
+	vec
+}
+
+func (r *Registry) Counter(name string, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newVec(name, help, labels)}
+	r.register(c)
+	return c
+}
+
+func (c *CounterVec) Add(delta float64, values ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.name))
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.with(values).value += delta
+}
+
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+func (c *CounterVec) Value(values ...string) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if s, ok := c.series[strings.Join(values, "\xff")]; ok {
+		return s.value
+	}
+
+	return 0
+}
+
+func (c *CounterVec) gather() Family {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	f := Family{Name: c.name, Help: c.help, Type: CounterType}
+	for _, s := range c.sorted() {
+		f.Samples = append(f.Samples, Sample{Name: c.name, Labels: c.labelPairs(s.values), Value: s.value})
+	}
+
+	return f
+}
+
+type GaugeVec struct {
+	vec
+}
+
+func (r *Registry) Gauge(name string, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newVec(name, help, labels)}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeVec) Set(value float64, values ...string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.with(values).value = value
+}
+
+func (g *GaugeVec) Add(delta float64, values ...string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.with(values).value += delta
+}
+
+func (g *GaugeVec) Delete(values ...string) {
+	g.delete(values)
+}
+
+func (g *GaugeVec) gather() Family {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	f := Family{Name: g.name, Help: g.help, Type: GaugeType}
+	for _, s := range g.sorted() {
+		f.Samples = append(f.Samples, Sample{Name: g.name, Labels: g.labelPairs(s.values), Value: s.value})
+	}
+
+	return f
+}
+
+// 出力するときに値を集めるゲージ。キューの長さのように書き込み側で追いにくい値に使う
+type GaugeFunc struct {
+	name    string
+	help    string
+	labels  []string
+	collect func(set func(value float64, values ...string))
+}
+
+func (r *Registry) GaugeFunc(name string, help string, collect func(set func(value float64, values ...string)), labels ...string) *GaugeFunc {
+	g := &GaugeFunc{name: name, help: help, labels: labels, collect: collect}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeFunc) gather() Family {
+	v := newVec(g.name, g.help, g.labels)
+	g.collect(func(value float64, values ...string) {
+		v.with(values).value += value
+	})
+
+	f := Family{Name: g.name, Help: g.help, Type: GaugeType}
+	for _, s := range v.sorted() {
+		f.Samples = append(f.Samples, Sample{Name: g.name, Labels: v.labelPairs(s.values), Value: s.value})
+	}
+
+	return f
+}
+
+type HistogramVec struct {
+	vec
+	buckets []float64
+}
+
+func (r *Registry) Histogram(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	h := &HistogramVec{vec: newVec(name, help, labels), buckets: buckets}
+	r.register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	s := h.with(values)
+	if s.buckets == nil {
+		s.buckets = make([]uint64, len(h.buckets))
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.buckets[i]++
+			break
+		}
+	}
+	s.count++
+	s.value += value
+}
+
+func (h *HistogramVec) gather() Family {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	f := Family{Name: h.name, Help: h.help, Type: HistogramType}
+	for _, s := range h.sorted() {
+		labels := h.labelPairs(s.values)
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.buckets[i]
+			f.Samples = append(f.Samples, Sample{Name: h.name + "_bucket", Labels: withLabel(labels, "le", formatFloat(bound)), Value: float64(cumulative)})
+		}
+		f.Samples = append(f.Samples,
+			Sample{Name: h.name + "_bucket", Labels: withLabel(labels, "le", "+Inf"), Value: float64(s.count)},
+			Sample{Name: h.name + "_sum", Labels: labels, Value: s.value},
+			Sample{Name: h.name + "_count", Labels: labels, Value: float64(s.count)},
+		)
+	}
+
+	return f
+}
+
+func withLabel(labels []Label, name string, value string) []Label {
+	return append(append([]Label{}, labels...), Label{Name: name, Value: value})
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	requests := r.Counter("requests_total", "Requests sent.", "class", "code")
+	requests.Inc("graphql", "200")
+	requests.Add(2, "graphql", "429")
+	requests.Inc("profile", "200")
+
+	delay := r.Gauge("delay_seconds", "Current delay.", "target")
+	delay.Set(1.5, "a\"b")
+
+	r.GaugeFunc("queue_length", "Queued items.", func(set func(float64, ...string)) {
+		set(3, "page")
+		set(4, "page")
+	}, "queue")
+
+	duration := r.Histogram("duration_seconds", "Request duration.", []float64{0.1, 1})
+	duration.Observe(0.05)
+	duration.Observe(0.5)
+	duration.Observe(3)
+
+	buffer := &bytes.Buffer{}
+	if err := r.WriteText(buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP requests_total Requests sent.
+# TYPE requests_total counter
+requests_total{class="graphql",code="200"} 1
+requests_total{class="graphql",code="429"} 2
+requests_total{class="profile",code="200"} 1
+# HELP delay_seconds Current delay.
+# TYPE delay_seconds gauge
+delay_seconds{target="a\"b"} 1.5
+# HELP queue_length Queued items.
+# TYPE queue_length gauge
+queue_length{queue="page"} 7
+# HELP duration_seconds Request duration.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{le="0.1"} 1
+duration_seconds_bucket{le="1"} 2
+duration_seconds_bucket{le="+Inf"} 3
+duration_seconds_sum 3.55
+duration_seconds_count 3
+`
+	if buffer.String() != expected {
+		t.Errorf("unexpected output\n%s", buffer.String())
+	}
+
+	if requests.Value("graphql", "429") != 2 {
+		t.Errorf("unexpected value %v", requests.Value("graphql", "429"))
+	}
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+type AtomicFile struct {
+	*os.File
+	path string
+	perm os.FileMode
+}
+
+func CreateAtomic(path string) (*AtomicFile, error) {
+	return createAtomic(path, 0644)
+}
+
+// 途中で中断しても壊れないよう、一時ファイルに書き出してから置き換える
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	file, err := createAtomic(path, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err = file.Write(data); err != nil {
+		file.Abort()
+		return err
+	}
+
+	return file.Close()
+}
+
+func createAtomic(path string, perm os.FileMode) (*AtomicFile, error) {
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	return &AtomicFile{File: file, path: path, perm: perm}, nil
+}
+
+func (f *AtomicFile) Close() error {
+	if err := f.File.Sync(); err != nil {
+		f.Abort()
+		return err
+	}
+
+	if err := f.File.Close(); err != nil {
+		os.Remove(f.File.Name())
+		return err
+	}
+
+	if err := os.Chmod(f.File.Name(), f.perm); err != nil {
+		os.Remove(f.File.Name())
+		return err
+	}
+
+	return os.Rename(f.File.Name(), f.path)
+}
+
+func (f *AtomicFile) Abort() error {
+	f.File.Close()
+	return os.Remove(f.File.Name())
+}
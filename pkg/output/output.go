@@ -0,0 +1,283 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+type Format string
+
+const (
+	JSON      Format = "json"
+	JSONLines Format = "jsonl"
+	NDJSON    Format = "ndjson"
+	CSV       Format = "csv"
+	TSV       Format = "tsv"
+)
+
+type Options struct {
+	Format  Format
+	Columns []string // CSV/TSV columns, defaults to the fields of the first record
+	Gzip    bool
+}
+
+type Writer interface {
+	Write(record interface{}) error
+	Close() error
+}
+
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(strings.ToLower(s)); format {
+	case JSON, JSONLines, NDJSON, CSV, TSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown output format: %s", s)
+	}
+}
+
+// レコードを書き込むたびに出力する。Closeしても元のio.Writerは閉じない
+func New(w io.Writer, options Options) (Writer, error) {
+	var closers []io.Closer
+	if options.Gzip {
+		gz := gzip.NewWriter(w)
+		w = gz
+		closers = append(closers, gz)
+	}
+
+	var writer Writer
+	switch options.Format {
+	case JSON, "":
+		writer = &jsonArrayWriter{w: w}
+	case JSONLines, NDJSON:
+		writer = &jsonLinesWriter{encoder: json.NewEncoder(w)}
+	case CSV:
+		writer = newDelimitedWriter(w, ',', options.Columns)
+	case TSV:
+		writer = newDelimitedWriter(w, '\t', options.Columns)
+	default:
+		return nil, fmt.Errorf("unknown output format: %s", options.Format)
+	}
+
+	return &closingWriter{Writer: writer, closers: closers}, nil
+}
+
+// 一時ファイルに書き出し、Closeで置き換える。拡張子が.gzの場合は圧縮する
+func Create(path string, options Options) (Writer, error) {
+	file, err := CreateAtomic(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(path, ".gz") {
+		options.Gzip = true
+	}
+
+	writer, err := New(file, options)
+	if err != nil {
+		file.Abort()
+		return nil, err
+	}
+
+	return &closingWriter{Writer: writer, closers: []io.Closer{file}, abort: file.Abort}, nil
+}
+
+type closingWriter struct {
+	Writer
+	closers []io.Closer
+	abort   func() error
+}
+
+func (w *closingWriter) Close() error {
+	if err := w.Writer.Close(); err != nil {
+		if w.abort != nil {
+			w.abort()
+		}
+		return err
+	}
+
+	for _, closer := range w.closers {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type jsonArrayWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (w *jsonArrayWriter) Write(record interface{}) error {
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n"
+	if w.count == 0 {
+		separator = "[\n"
+	}
+	w.count++
+
+	if _, err = io.WriteString(w.w, separator); err != nil {
+		return err
+	}
+	_, err = w.w.Write(bytes)
+	return err
+}
+
+func (w *jsonArrayWriter) Close() error {
+	if w.count == 0 {
+		_, err := io.WriteString(w.w, "[]\n")
+		return err
+	}
+
+	_, err := io.WriteString(w.w, "\n]\n")
+	return err
+}
+
+type jsonLinesWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *jsonLinesWriter) Write(record interface{}) error {
+	return w.encoder.Encode(record)
+}
+
+func (w *jsonLinesWriter) Close() error {
+	return nil
+}
+
+type delimitedWriter struct {
+	csv     *csv.Writer
+	columns []string
+	header  bool
+}
+
+func newDelimitedWriter(w io.Writer, comma rune, columns []string) *delimitedWriter {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+	return &delimitedWriter{csv: writer, columns: columns}
+}
+
+func (w *delimitedWriter) Write(record interface{}) error {
+	keys, values, err := fields(record)
+	if err != nil {
+		return err
+	}
+
+	if !w.header {
+		if len(w.columns) == 0 {
+			w.columns = columns(reflect.TypeOf(record))
+		}
+		if len(w.columns) == 0 {
+			w.columns = keys
+		}
+		if err = w.csv.Write(w.columns); err != nil {
+			return err
+		}
+		w.header = true
+	}
+
+	row := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		row[i] = values[column]
+	}
+
+	if err = w.csv.Write(row); err != nil {
+		return err
+	}
+
+	// ストリーミングのため1行ごとに書き出す
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *delimitedWriter) Close() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// omitemptyで最初のレコードに無いフィールドも列にするため、型のJSONタグから列を決める
+func columns(t reflect.Type) []string {
+	if t == nil {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			names = append(names, columns(field.Type)...)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// JSONタグの順にフィールド名と値を取り出す
+func fields(record interface{}) ([]string, map[string]string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, nil, fmt.Errorf("record must be a JSON object: %s", string(data))
+	}
+
+	var keys []string
+	values := map[string]string{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := token.(string)
+
+		var raw json.RawMessage
+		if err = decoder.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			values[key] = s
+		} else {
+			values[key] = string(raw)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, values, nil
+}
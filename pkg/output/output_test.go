@@ -0,0 +1,120 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type record struct {
+	Url       string `json:"url"`
+	Timestamp int32  `json:"timestamp"`
+	IsVideo   bool   `json:"is_video"`
+}
+
+var records = []record{
+	{"https://example.com/a.jpg", 1, false},
+	{"https://example.com/b,c.mp4", 2, true},
+}
+
+func write(t *testing.T, options Options) string {
+	buffer := &bytes.Buffer{}
+	writer, err := New(buffer, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range records {
+		if err := writer.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.String()
+}
+
+func TestFormats(t *testing.T) {
+	cases := []struct {
+		options  Options
+		expected string
+	}{
+		{Options{Format: JSON}, "[\n{\"url\":\"https://example.com/a.jpg\",\"timestamp\":1,\"is_video\":false},\n{\"url\":\"https://example.com/b,c.mp4\",\"timestamp\":2,\"is_video\":true}\n]\n"},
+		{Options{Format: JSONLines}, "{\"url\":\"https://example.com/a.jpg\",\"timestamp\":1,\"is_video\":false}\n{\"url\":\"https://example.com/b,c.mp4\",\"timestamp\":2,\"is_video\":true}\n"},
+		{Options{Format: CSV}, "url,timestamp,is_video\nhttps://example.com/a.jpg,1,false\n\"https://example.com/b,c.mp4\",2,true\n"},
+		{Options{Format: TSV, Columns: []string{"is_video", "url"}}, "is_video\turl\nfalse\thttps://example.com/a.jpg\ntrue\thttps://example.com/b,c.mp4\n"},
+	}
+
+	for _, c := range cases {
+		if actual := write(t, c.options); actual != c.expected {
+			t.Errorf("%s: got %q, want %q", c.options.Format, actual, c.expected)
+		}
+	}
+}
+
+func TestEmptyJSON(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	writer, _ := New(buffer, Options{Format: JSON})
+	writer.Close()
+	if buffer.String() != "[]\n" {
+		t.Errorf("got %q", buffer.String())
+	}
+}
+
+func TestCreateGzip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "resources.jsonl.gz")
+	writer, err := Create(path, Options{Format: JSONLines})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write(records[0])
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("file must not exist before Close")
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadAll(reader)
+	if string(content) != "{\"url\":\"https://example.com/a.jpg\",\"timestamp\":1,\"is_video\":false}\n" {
+		t.Errorf("got %q", content)
+	}
+}
+
+func TestCSVColumnsFromType(t *testing.T) {
+	type optional struct {
+		Url       string `json:"url"`
+		ViewCount int    `json:"view_count,omitempty"`
+		Skipped   string `json:"-"`
+	}
+
+	buffer := &bytes.Buffer{}
+	writer, _ := New(buffer, Options{Format: CSV})
+	writer.Write(optional{Url: "a"})
+	writer.Write(&optional{Url: "b", ViewCount: 3})
+	writer.Close()
+
+	if expected := "url,view_count\na,\nb,3\n"; buffer.String() != expected {
+		t.Errorf("got %q, want %q", buffer.String(), expected)
+	}
+}
@@ -0,0 +1,371 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	netproxy "golang.org/x/net/proxy"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultMaxFailures = 3
+const DefaultCoolDown = 10 * time.Minute
+const DialTimeout = 30 * time.Second
+
+type Rotation string
+
+const (
+	PerCrawl   Rotation = "per-crawl"   // クロールごとに選び、429か接続エラーになるまで使い続ける
+	PerRequest Rotation = "per-request" // リクエストごとに順番に選ぶ
+)
+
+func ParseRotation(s string) (Rotation, error) {
+	switch rotation := Rotation(s); rotation {
+	case PerCrawl, PerRequest:
+		return rotation, nil
+	}
+
+	return "", fmt.Errorf("unknown proxy rotation \"%s\"", s)
+}
+
+type Result string
+
+const (
+	Succeeded Result = "succeeded"
+	Throttled Result = "throttled" // 429が返ってきた
+	Failed    Result = "failed"    // プロキシか宛先に接続できなかった
+)
+
+type Options struct {
+	Rotation    Rotation      // 空ならPerCrawl
+	MaxFailures int           // 続けてこの回数429か接続エラーになったら休ませる。0ならDefaultMaxFailures
+	CoolDown    time.Duration // 休ませる時間。0ならDefaultCoolDown
+}
+
+// リクエストごとの結果
+type Event struct {
+	Proxy    string
+	Result   Result
+	CoolDown time.Duration // この結果で休ませ始めた場合はその時間
+}
+
+type Stats struct {
+	Proxy            string    `json:"proxy"` // 認証情報は除く
+	Requests         int       `json:"requests"`
+	Throttled        int       `json:"throttled"`
+	Failed           int       `json:"failed"`
+	CoolDowns        int       `json:"cool_downs"`
+	CoolingDownUntil time.Time `json:"cooling_down_until"` // 休ませていなければゼロ
+}
+
+type proxy struct {
+	name      string
+	transport *http.Transport
+	stats     Stats
+	failures  int // 続けて429か接続エラーになった回数
+	until     time.Time
+}
+
+// 複数のクロールから並行に使える
+type Pool struct {
+	options Options
+	proxies []*proxy
+	now     func() time.Time
+
+	mutex sync.Mutex
+	next  int
+}
+
+// http、https、socks5のURLを受け付ける。認証情報はURLに含める
+func New(urls []string, options Options) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no proxies to rotate")
+	}
+	if options.Rotation == "" {
+		options.Rotation = PerCrawl
+	}
+	if _, err := ParseRotation(string(options.Rotation)); err != nil {
+		return nil, err
+	}
+	if options.MaxFailures < 0 || options.CoolDown < 0 {
+		return nil, fmt.Errorf("proxy max failures and cool-down must not be negative")
+	}
+	if options.MaxFailures == 0 {
+		options.MaxFailures = DefaultMaxFailures
+	}
+	if options.CoolDown == 0 {
+		options.CoolDown = DefaultCoolDown
+	}
+
+	pool := &Pool{options: options, now: time.Now}
+	for _, raw := range urls {
+		u, err := Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		transport, err := newTransport(u)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't use proxy \"%s\"", redact(u))
+		}
+		pool.proxies = append(pool.proxies, &proxy{name: redact(u), transport: transport})
+	}
+
+	return pool, nil
+}
+
+func Parse(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid proxy")
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme \"%s\"", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("proxy \"%s\" has no host", redact(u))
+	}
+
+	return u, nil
+}
+
+// 1行に1つのURLを書いたファイルを読む。空行と#で始まる行は飛ばす
+func ReadList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var urls []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+
+	return urls, scanner.Err()
+}
+
+func newTransport(u *url.URL) (*http.Transport, error) {
+	dialer := &net.Dialer{Timeout: DialTimeout, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+
+	if u.Scheme == "http" || u.Scheme == "https" {
+		transport.Proxy = http.ProxyURL(u)
+		return transport, nil
+	}
+
+	var auth *netproxy.Auth
+	if u.User != nil {
+		password, _ := u.User.Password()
+		auth = &netproxy.Auth{User: u.User.Username(), Password: password}
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "1080")
+	}
+
+	socks, err := netproxy.SOCKS5("tcp", address, auth, dialer)
+	if err != nil {
+		return nil, err
+	}
+	contextDialer, ok := socks.(netproxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("socks5 dialer doesn't support contexts")
+	}
+	transport.DialContext = contextDialer.DialContext
+
+	return transport, nil
+}
+
+// ログや統計にパスワードを出さない
+func redact(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+
+	redacted := *u
+	redacted.User = url.User(u.User.Username())
+	return redacted.String()
+}
+
+func (p *Pool) Rotation() Rotation {
+	return p.options.Rotation
+}
+
+func (p *Pool) Stats() []Stats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	stats := make([]Stats, 0, len(p.proxies))
+	for _, proxy := range p.proxies {
+		s := proxy.stats
+		s.Proxy = proxy.name
+		if proxy.until.After(now) {
+			s.CoolingDownUntil = proxy.until
+		}
+		stats = append(stats, s)
+	}
+
+	return stats
+}
+
+// 休ませていないプロキシが残っているか
+func (p *Pool) Available() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	for _, proxy := range p.proxies {
+		if !proxy.until.After(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// currentが使えればそのまま返す。全て休んでいる場合は最初に戻るまでの時間を返す
+func (p *Pool) pick(current *proxy) (*proxy, time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	if current != nil && !current.until.After(now) {
+		return current, 0
+	}
+
+	var wait time.Duration
+	for i := range p.proxies {
+		proxy := p.proxies[(p.next+i)%len(p.proxies)]
+		if !proxy.until.After(now) {
+			p.next = (p.next + i + 1) % len(p.proxies)
+			return proxy, 0
+		}
+		if d := proxy.until.Sub(now); wait == 0 || d < wait {
+			wait = d
+		}
+	}
+
+	return nil, wait
+}
+
+func (p *Pool) record(proxy *proxy, response *http.Response, err error) Event {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	event := Event{Proxy: proxy.name, Result: Succeeded}
+	switch {
+	case err != nil:
+		event.Result = Failed
+		proxy.stats.Failed++
+	case response.StatusCode == http.StatusTooManyRequests:
+		event.Result = Throttled
+		proxy.stats.Throttled++
+	}
+	proxy.stats.Requests++
+
+	if event.Result == Succeeded {
+		proxy.failures = 0
+		return event
+	}
+
+	proxy.failures++
+	if proxy.failures >= p.options.MaxFailures {
+		log.Printf("proxy %s is cooling down for %s", proxy.name, p.options.CoolDown)
+		proxy.failures = 0
+		proxy.until = p.now().Add(p.options.CoolDown)
+		proxy.stats.CoolDowns++
+		event.CoolDown = p.options.CoolDown
+	}
+
+	return event
+}
+
+// プールから選んだプロキシでリクエストを送る。PerCrawlで同じものを使い続けられるようにクロールごとに作る
+type Transport struct {
+	OnEvent func(Event)
+
+	pool    *Pool
+	mutex   sync.Mutex
+	current *proxy
+}
+
+func (p *Pool) Transport() *Transport {
+	return &Transport{pool: p}
+}
+
+func (t *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	proxy, err := t.proxy(request.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := proxy.transport.RoundTrip(request)
+	// 中断したリクエストはプロキシのせいではない
+	if err != nil && request.Context().Err() != nil {
+		return nil, err
+	}
+
+	event := t.pool.record(proxy, response, err)
+	// 失敗したら次のリクエストは別のプロキシで送る
+	if event.Result != Succeeded {
+		t.mutex.Lock()
+		if t.current == proxy {
+			t.current = nil
+		}
+		t.mutex.Unlock()
+	}
+	if t.OnEvent != nil {
+		t.OnEvent(event)
+	}
+
+	return response, err
+}
+
+// 全て休んでいる場合は最初に戻るものを待つ
+func (t *Transport) proxy(ctx context.Context) (*proxy, error) {
+	for {
+		t.mutex.Lock()
+		current := t.current
+		t.mutex.Unlock()
+		if t.pool.options.Rotation != PerCrawl {
+			current = nil
+		}
+
+		proxy, wait := t.pool.pick(current)
+		if proxy != nil {
+			t.mutex.Lock()
+			t.current = proxy
+			t.mutex.Unlock()
+			return proxy, nil
+		}
+
+		log.Printf("all proxies are cooling down, waiting %s", wait)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kouheiszk/ig-crawler"
+	"github.com/kouheiszk/ig-crawler/pkg/webhook"
+	"io/ioutil"
+	"time"
+)
+
+// JSONでは "1h30m" のような文字列で書く
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"1h\": %s", string(b))
+	}
+
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+type Config struct {
+	History    string             `json:"history,omitempty"`     // 実行履歴を追記するJSON Linesファイル
+	State      string             `json:"state,omitempty"`       // アカウントごとに取得済みの最新の投稿時刻を保存するファイル
+	Webhooks   []webhook.Endpoint `json:"webhooks,omitempty"`    // 新しい投稿が見つかったときとクロールが失敗したときに通知する
+	DeadLetter string             `json:"dead_letter,omitempty"` // 配信できなかった通知を追記するJSON Linesファイル
+	Jobs       []JobConfig        `json:"jobs"`
+}
+
+// アカウントのグループごとにスケジュールとクロールの設定を持つ
+type JobConfig struct {
+	Name      string   `json:"name"`
+	Usernames []string `json:"usernames"`
+	Cron      string   `json:"cron,omitempty"`
+	Interval  Duration `json:"interval,omitempty"`
+	Jitter    Duration `json:"jitter,omitempty"`
+	Options   Options  `json:"options"`
+}
+
+type Options struct {
+	Highlights     bool `json:"highlights,omitempty"`
+	Stories        bool `json:"stories,omitempty"`
+	Tagged         bool `json:"tagged,omitempty"`
+	IGTV           bool `json:"igtv,omitempty"`
+	Reels          bool `json:"reels,omitempty"`
+	Comments       bool `json:"comments,omitempty"`
+	MaxComments    int  `json:"max_comments,omitempty"`
+	MaxConnections int  `json:"max_connections,omitempty"`
+}
+
+func (o *Options) config(username string) *crawler.Config {
+	return &crawler.Config{
+		Username:       username,
+		Highlights:     o.Highlights,
+		Stories:        o.Stories,
+		Tagged:         o.Tagged,
+		IGTV:           o.IGTV,
+		Reels:          o.Reels,
+		Comments:       o.Comments,
+		MaxComments:    o.MaxComments,
+		MaxConnections: o.MaxConnections,
+	}
+}
+
+func LoadConfig(path string) (*Config, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	if err = json.Unmarshal(bytes, config); err != nil {
+		return nil, fmt.Errorf("invalid schedule config \"%s\": %v", path, err)
+	}
+
+	if err = config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid schedule config \"%s\": %v", path, err)
+	}
+
+	return config, nil
+}
+
+func (c *Config) validate() error {
+	names := map[string]bool{}
+	for i := range c.Jobs {
+		job := &c.Jobs[i]
+		if len(job.Usernames) == 0 {
+			return fmt.Errorf("job %d has no usernames", i)
+		}
+		if job.Name == "" {
+			job.Name = job.Usernames[0]
+		}
+		if names[job.Name] {
+			return fmt.Errorf("duplicate job name \"%s\"", job.Name)
+		}
+		names[job.Name] = true
+
+		if _, err := job.schedule(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (j *JobConfig) schedule() (Schedule, error) {
+	switch {
+	case j.Cron != "" && j.Interval != 0:
+		return nil, fmt.Errorf("job \"%s\" has both cron and interval", j.Name)
+	case j.Cron != "":
+		return ParseCron(j.Cron)
+	case j.Interval > 0:
+		return Every(j.Interval), nil
+	default:
+		return nil, fmt.Errorf("job \"%s\" needs cron or interval", j.Name)
+	}
+}
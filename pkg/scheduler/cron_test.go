@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2018, 12, 5, 8, 53, 20, 0, time.UTC) // Wednesday
+	cases := []struct {
+		expression string
+		next       string
+	}{
+		{"* * * * *", "2018-12-05 08:54"},
+		{"0 * * * *", "2018-12-05 09:00"},
+		{"*/15 * * * *", "2018-12-05 09:00"},
+		{"30 6 * * *", "2018-12-06 06:30"},
+		{"0 0 1 * *", "2019-01-01 00:00"},
+		{"0 9 * * mon-fri", "2018-12-05 09:00"},
+		{"0 8 * * mon-fri", "2018-12-06 08:00"},
+		{"0 9 * * 0", "2018-12-09 09:00"},
+		{"0 9 * * 7", "2018-12-09 09:00"},
+		{"0 0 31 * 1", "2018-12-10 00:00"},
+		{"0 0 29 feb *", "2020-02-29 00:00"},
+		{"@daily", "2018-12-06 00:00"},
+	}
+
+	for _, c := range cases {
+		cron, err := ParseCron(c.expression)
+		if err != nil {
+			t.Errorf("%s: %v", c.expression, err)
+			continue
+		}
+		if next := cron.Next(from).Format("2006-01-02 15:04"); next != c.next {
+			t.Errorf("%s: got %s, want %s", c.expression, next, c.next)
+		}
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		if _, err := ParseCron(expression); err == nil {
+			t.Errorf("%q: expected error", expression)
+		}
+	}
+}
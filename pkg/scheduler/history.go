@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/kouheiszk/ig-crawler/pkg/output"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+type RunStatus string
+
+const (
+	Succeeded RunStatus = "succeeded"
+	Failed    RunStatus = "failed"
+	Skipped   RunStatus = "skipped"  // 前回の実行が終わっていなかった
+	Canceled  RunStatus = "canceled" // 実行中にスケジューラが止まった
+)
+
+type Run struct {
+	Job         string     `json:"job"`
+	Username    string     `json:"username"`
+	Status      RunStatus  `json:"status"`
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	After       int32      `json:"after"`
+	Resources   int        `json:"resources"`
+	Error       string     `json:"error,omitempty"`
+}
+
+type history struct {
+	sync.Mutex
+	path string
+}
+
+func (h *history) append(run Run) error {
+	if h.path == "" {
+		return nil
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	file, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err = json.NewEncoder(file).Encode(run); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func LoadHistory(path string) ([]Run, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		run := Run{}
+		if err = json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			return nil, fmt.Errorf("invalid run history \"%s\": %v", path, err)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, scanner.Err()
+}
+
+// 次の実行で新しい投稿だけを取得するため、アカウントごとに最新の投稿時刻を持つ
+type state struct {
+	sync.Mutex
+	path  string
+	after map[string]int32
+}
+
+func loadState(path string) (*state, error) {
+	s := &state{path: path, after: map[string]int32{}}
+	if path == "" {
+		return s, nil
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(bytes, &s.after); err != nil {
+		return nil, fmt.Errorf("invalid schedule state \"%s\": %v", path, err)
+	}
+
+	return s, nil
+}
+
+func (s *state) get(username string) int32 {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.after[username]
+}
+
+func (s *state) update(username string, after int32) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if after <= s.after[username] {
+		return nil
+	}
+	s.after[username] = after
+
+	if s.path == "" {
+		return nil
+	}
+
+	bytes, err := json.MarshalIndent(s.after, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return output.WriteFileAtomic(s.path, bytes, 0644)
+}
@@ -0,0 +1,229 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/kouheiszk/ig-crawler"
+	"github.com/kouheiszk/ig-crawler/pkg/webhook"
+	"log"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// スケジューラを止めても配信し終えられるよう、Runのctxとは別に持つ
+const NotifyTimeout = time.Minute
+
+type Scheduler struct {
+	config  *Config
+	base    *crawler.Config
+	targets []*target
+	state   *state
+	history *history
+	webhook *webhook.Notifier
+	wg      sync.WaitGroup
+
+	randMutex sync.Mutex
+	rand      *rand.Rand
+
+	crawl func(ctx context.Context, config *crawler.Config) ([]crawler.Resource, []crawler.Post, error)
+}
+
+type target struct {
+	job      *JobConfig
+	username string
+	schedule Schedule
+	running  int32
+}
+
+// baseにはセッションなど全てのジョブに共通の設定を渡す
+func New(config *Config, base *crawler.Config) (*Scheduler, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	state, err := loadState(config.State)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Scheduler{
+		config:  config,
+		base:    base,
+		state:   state,
+		history: &history{path: config.History},
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		crawl:   crawler.FetchResourcesAndPostsWithContext,
+	}
+	if s.base == nil {
+		s.base = &crawler.Config{}
+	}
+	if len(config.Webhooks) > 0 {
+		s.webhook = webhook.New(webhook.Options{Endpoints: config.Webhooks, DeadLetter: config.DeadLetter})
+	}
+
+	for i := range config.Jobs {
+		job := &config.Jobs[i]
+		schedule, err := job.schedule()
+		if err != nil {
+			return nil, err
+		}
+		for _, username := range job.Usernames {
+			s.targets = append(s.targets, &target{job: job, username: username, schedule: schedule})
+		}
+	}
+
+	return s, nil
+}
+
+// ctxが終わるまでスケジュールに従ってクロールし、実行中のクロールの終了を待って戻る
+func (s *Scheduler) Run(ctx context.Context) {
+	loops := sync.WaitGroup{}
+	for _, t := range s.targets {
+		loops.Add(1)
+		go func(t *target) {
+			defer loops.Done()
+			s.loop(ctx, t)
+		}(t)
+	}
+
+	loops.Wait()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, t *target) {
+	for {
+		scheduledAt := t.schedule.Next(time.Now())
+		if scheduledAt.IsZero() {
+			return
+		}
+		scheduledAt = scheduledAt.Add(s.jitter(t.job.Jitter))
+
+		timer := time.NewTimer(time.Until(scheduledAt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// 前回の実行が続いていれば今回は飛ばす
+		if !atomic.CompareAndSwapInt32(&t.running, 0, 1) {
+			s.record(Run{Job: t.job.Name, Username: t.username, Status: Skipped, ScheduledAt: scheduledAt, After: s.state.get(t.username)})
+			continue
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer atomic.StoreInt32(&t.running, 0)
+			s.run(ctx, t, scheduledAt)
+		}()
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, t *target, scheduledAt time.Time) {
+	after := s.state.get(t.username)
+	config := (&crawler.Config{}).Merge(s.base).Merge(t.job.Options.config(t.username))
+	config.After = after
+
+	startedAt := time.Now()
+	resources, posts, err := s.crawl(ctx, config)
+	finishedAt := time.Now()
+
+	run := Run{
+		Job:         t.job.Name,
+		Username:    t.username,
+		Status:      Succeeded,
+		ScheduledAt: scheduledAt,
+		StartedAt:   &startedAt,
+		FinishedAt:  &finishedAt,
+		After:       after,
+		Resources:   len(resources),
+	}
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// 止めたことによる中断は失敗として通知しない
+		run.Status = Canceled
+		s.record(run)
+		return
+	case err != nil:
+		run.Status = Failed
+		run.Error = err.Error()
+	default:
+		latest := after
+		for _, resource := range resources {
+			if resource.Timestamp > latest {
+				latest = resource.Timestamp
+			}
+		}
+		if err = s.state.update(t.username, latest); err != nil {
+			log.Print(err)
+		}
+	}
+
+	s.record(run)
+	s.notify(run, resources, posts)
+}
+
+// 失敗したときと、前回より新しい投稿が見つかったときだけ通知する
+func (s *Scheduler) notify(run Run, resources []crawler.Resource, posts []crawler.Post) {
+	if s.webhook == nil {
+		return
+	}
+
+	payload := &webhook.Payload{
+		Event:      webhook.NewPostsEvent,
+		Job:        run.Job,
+		Username:   run.Username,
+		Status:     string(run.Status),
+		Error:      run.Error,
+		StartedAt:  *run.StartedAt,
+		FinishedAt: *run.FinishedAt,
+		Resources:  []crawler.Resource{},
+		Posts:      []crawler.Post{},
+	}
+	if run.Status == Failed {
+		payload.Event = webhook.CrawlFailedEvent
+	} else {
+		for _, post := range posts {
+			if post.Timestamp > run.After {
+				payload.Posts = append(payload.Posts, post)
+			}
+		}
+		if len(payload.Posts) == 0 {
+			return
+		}
+		for _, resource := range resources {
+			if resource.Timestamp > run.After {
+				payload.Resources = append(payload.Resources, resource)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), NotifyTimeout)
+	defer cancel()
+
+	// 配信できなかったものはデッドレターに残っているので、ここではログに出すだけにする
+	if err := s.webhook.Notify(ctx, payload); err != nil {
+		log.Printf("%s %s: webhook: %v", run.Job, run.Username, err)
+	}
+}
+
+func (s *Scheduler) record(run Run) {
+	log.Printf("%s %s: %s", run.Job, run.Username, run.Status)
+	if err := s.history.append(run); err != nil {
+		log.Print(err)
+	}
+}
+
+func (s *Scheduler) jitter(max Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+
+	s.randMutex.Lock()
+	defer s.randMutex.Unlock()
+
+	return time.Duration(s.rand.Int63n(int64(max)))
+}
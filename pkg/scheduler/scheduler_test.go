@@ -0,0 +1,222 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kouheiszk/ig-crawler"
+	"github.com/kouheiszk/ig-crawler/pkg/webhook"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := &Config{
+		History: filepath.Join(dir, "history.jsonl"),
+		State:   filepath.Join(dir, "state.json"),
+		Jobs: []JobConfig{
+			{Usernames: []string{"kouheiszk"}, Interval: Duration(20 * time.Millisecond), Options: Options{Tagged: true}},
+		},
+	}
+
+	s, err := New(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mutex sync.Mutex
+	var afters []int32
+	s.crawl = func(ctx context.Context, config *crawler.Config) ([]crawler.Resource, []crawler.Post, error) {
+		mutex.Lock()
+		afters = append(afters, config.After)
+		calls := len(afters)
+		mutex.Unlock()
+
+		if !config.Tagged {
+			t.Error("job options are not applied")
+		}
+		// 1回目は次の予定より長くかかる
+		if calls == 1 {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return []crawler.Resource{{Timestamp: int32(100 * calls)}}, nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	runs, err := LoadHistory(config.History)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := map[RunStatus]int{}
+	for _, run := range runs {
+		statuses[run.Status]++
+	}
+	if statuses[Skipped] == 0 || statuses[Succeeded] < 2 {
+		t.Errorf("unexpected runs %+v", runs)
+	}
+	if len(afters) < 2 || afters[0] != 0 || afters[1] != 100 {
+		t.Errorf("runs must pick up only new posts, got afters %v", afters)
+	}
+
+	state, err := loadState(config.State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.get("kouheiszk") != int32(100*len(afters)) {
+		t.Errorf("unexpected state %v", state.after)
+	}
+}
+
+func TestSchedulerWebhook(t *testing.T) {
+	var mutex sync.Mutex
+	var payloads []webhook.Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := webhook.Payload{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		mutex.Lock()
+		payloads = append(payloads, payload)
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Webhooks: []webhook.Endpoint{{Url: server.URL, Secret: "secret"}},
+		Jobs: []JobConfig{
+			{Usernames: []string{"kouheiszk"}, Interval: Duration(10 * time.Millisecond)},
+		},
+	}
+
+	s, err := New(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int32
+	s.crawl = func(ctx context.Context, config *crawler.Config) ([]crawler.Resource, []crawler.Post, error) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			return []crawler.Resource{{Shortcode: "a", Timestamp: 100}}, []crawler.Post{{Shortcode: "a", Timestamp: 100}}, nil
+		case 2:
+			// 新しい投稿がなければ通知しない
+			return nil, nil, nil
+		default:
+			return nil, nil, fmt.Errorf("rate limited")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(payloads) < 2 {
+		t.Fatalf("unexpected payloads %+v", payloads)
+	}
+	if payloads[0].Event != webhook.NewPostsEvent || len(payloads[0].Posts) != 1 || len(payloads[0].Resources) != 1 {
+		t.Errorf("unexpected new posts payload %+v", payloads[0])
+	}
+	if payloads[1].Event != webhook.CrawlFailedEvent || payloads[1].Error != "rate limited" {
+		t.Errorf("unexpected failure payload %+v", payloads[1])
+	}
+}
+
+func TestSchedulerShutdown(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := &Config{
+		History:    filepath.Join(dir, "history.jsonl"),
+		DeadLetter: filepath.Join(dir, "dead.jsonl"),
+		Webhooks:   []webhook.Endpoint{{Url: server.URL}},
+		Jobs: []JobConfig{
+			{Usernames: []string{"kouheiszk"}, Interval: Duration(10 * time.Millisecond)},
+		},
+	}
+
+	s, err := New(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.crawl = func(ctx context.Context, config *crawler.Config) ([]crawler.Resource, []crawler.Post, error) {
+		<-ctx.Done()
+		return nil, nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	runs, err := LoadHistory(config.History)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[RunStatus]int{}
+	for _, run := range runs {
+		statuses[run.Status]++
+	}
+	if statuses[Canceled] != 1 || statuses[Failed] != 0 {
+		t.Errorf("interrupted run must be recorded as canceled, got %+v", runs)
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("canceled runs must not be notified, got %d requests", n)
+	}
+	if _, err := os.Stat(config.DeadLetter); !os.IsNotExist(err) {
+		t.Errorf("no dead letters must be written, got %v", err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cases := []struct {
+		json  string
+		valid bool
+	}{
+		{`{"jobs":[{"usernames":["a","b"],"cron":"0 * * * *","jitter":"5m"}]}`, true},
+		{`{"jobs":[{"usernames":["a"],"interval":"1h"}]}`, true},
+		{`{"jobs":[{"usernames":["a"]}]}`, false},
+		{`{"jobs":[{"usernames":["a"],"cron":"0 * * * *","interval":"1h"}]}`, false},
+		{`{"jobs":[{"usernames":[],"interval":"1h"}]}`, false},
+		{`{"jobs":[{"usernames":["a"],"interval":"1h"},{"usernames":["a"],"interval":"2h"}]}`, false},
+		{`{"jobs":[{"usernames":["a"],"interval":3600}]}`, false},
+	}
+
+	dir, err := ioutil.TempDir("", "scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, c := range cases {
+		path := filepath.Join(dir, "schedule.json")
+		ioutil.WriteFile(path, []byte(c.json), 0644)
+		if _, err := LoadConfig(path); (err == nil) != c.valid {
+			t.Errorf("%s: unexpected error %v", c.json, err)
+		}
+	}
+}
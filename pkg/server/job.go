@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/kouheiszk/ig-crawler"
+	"sync"
+	"time"
+)
+
+type JobStatus string
+
+const (
+	Queued    JobStatus = "queued"
+	Running   JobStatus = "running"
+	Succeeded JobStatus = "succeeded"
+	Failed    JobStatus = "failed"
+	Canceled  JobStatus = "canceled"
+)
+
+func (s JobStatus) done() bool {
+	return s == Succeeded || s == Failed || s == Canceled
+}
+
+type CrawlRequest struct {
+	Username       string `json:"username"`
+	Hashtag        string `json:"hashtag,omitempty"`
+	Location       string `json:"location,omitempty"`
+	TopPosts       bool   `json:"top_posts,omitempty"`
+	After          int32  `json:"after,omitempty"`
+	Highlights     bool   `json:"highlights,omitempty"`
+	Stories        bool   `json:"stories,omitempty"`
+	Tagged         bool   `json:"tagged,omitempty"`
+	IGTV           bool   `json:"igtv,omitempty"`
+	Reels          bool   `json:"reels,omitempty"`
+	Comments       bool   `json:"comments,omitempty"`
+	MaxComments    int    `json:"max_comments,omitempty"`
+	MaxConnections int    `json:"max_connections,omitempty"`
+}
+
+func (r *CrawlRequest) config() *crawler.Config {
+	return &crawler.Config{
+		Username:       r.Username,
+		Hashtag:        r.Hashtag,
+		Location:       r.Location,
+		TopPosts:       r.TopPosts,
+		After:          r.After,
+		Highlights:     r.Highlights,
+		Stories:        r.Stories,
+		Tagged:         r.Tagged,
+		IGTV:           r.IGTV,
+		Reels:          r.Reels,
+		Comments:       r.Comments,
+		MaxComments:    r.MaxComments,
+		MaxConnections: r.MaxConnections,
+	}
+}
+
+type JobInfo struct {
+	Id         string       `json:"id"`
+	Status     JobStatus    `json:"status"`
+	Request    CrawlRequest `json:"request"`
+	Resources  int          `json:"resources"`
+	Error      string       `json:"error,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	StartedAt  *time.Time   `json:"started_at,omitempty"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+}
+
+type Job struct {
+	sync.Mutex
+	JobInfo
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	resources []crawler.Resource
+	changed   chan struct{}
+}
+
+func newJob(request CrawlRequest) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Job{
+		JobInfo: JobInfo{
+			Id:        newJobId(),
+			Status:    Queued,
+			Request:   request,
+			CreatedAt: time.Now(),
+		},
+		ctx:     ctx,
+		cancel:  cancel,
+		changed: make(chan struct{}),
+	}
+}
+
+func (j *Job) info() JobInfo {
+	j.Lock()
+	defer j.Unlock()
+
+	return j.JobInfo
+}
+
+// 変更を待っているストリームにchangedを閉じて知らせる
+func (j *Job) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+func (j *Job) start() bool {
+	j.Lock()
+	defer j.Unlock()
+
+	if j.Status != Queued {
+		return false
+	}
+
+	now := time.Now()
+	j.Status = Running
+	j.StartedAt = &now
+	j.notify()
+
+	return true
+}
+
+func (j *Job) add(resource crawler.Resource) {
+	j.Lock()
+	defer j.Unlock()
+
+	j.resources = append(j.resources, resource)
+	j.Resources = len(j.resources)
+	j.notify()
+}
+
+func (j *Job) finish(err error) {
+	j.Lock()
+	defer j.Unlock()
+
+	if j.Status.done() {
+		return
+	}
+
+	now := time.Now()
+	j.FinishedAt = &now
+	switch {
+	case j.ctx.Err() != nil:
+		j.Status = Canceled
+	case err != nil:
+		j.Status = Failed
+		j.Error = err.Error()
+	default:
+		j.Status = Succeeded
+	}
+	j.cancel()
+	j.notify()
+}
+
+// offset以降のリソースと、変更を待つためのチャネルを返す
+func (j *Job) resourcesFrom(offset int) ([]crawler.Resource, bool, <-chan struct{}) {
+	j.Lock()
+	defer j.Unlock()
+
+	return j.resources[offset:], j.Status.done(), j.changed
+}
+
+func newJobId() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,320 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kouheiszk/ig-crawler"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultQueueSize = 100
+const DefaultWorkers = 1
+const DefaultRetention = time.Hour
+const DefaultMaxFinished = 1000
+const DefaultMaxConnections = 4
+
+type Options struct {
+	QueueSize      int           // 待機できるジョブの数、超えたら503を返す
+	Workers        int           // 並行して実行するクロールの数
+	Retention      time.Duration // 終わったジョブを残しておく時間
+	MaxFinished    int           // 残しておく終わったジョブの数、超えたら古いものから消す
+	MaxConnections int           // リクエストで指定できるmax_connectionsの上限
+	Config         *crawler.Config
+}
+
+type Server struct {
+	options Options
+	queue   chan *Job
+	jobs    sync.Map
+	wg      sync.WaitGroup
+	mux     *http.ServeMux
+
+	closeOnce sync.Once
+	closed    chan struct{}
+
+	crawl func(ctx context.Context, config *crawler.Config) error
+}
+
+func New(options Options) *Server {
+	if options.QueueSize == 0 {
+		options.QueueSize = DefaultQueueSize
+	}
+	if options.Workers == 0 {
+		options.Workers = DefaultWorkers
+	}
+	if options.Retention == 0 {
+		options.Retention = DefaultRetention
+	}
+	if options.MaxFinished == 0 {
+		options.MaxFinished = DefaultMaxFinished
+	}
+	if options.MaxConnections == 0 {
+		options.MaxConnections = DefaultMaxConnections
+	}
+	if options.Config == nil {
+		options.Config = &crawler.Config{}
+	}
+
+	s := &Server{
+		options: options,
+		queue:   make(chan *Job, options.QueueSize),
+		mux:     http.NewServeMux(),
+		closed:  make(chan struct{}),
+		crawl: func(ctx context.Context, config *crawler.Config) error {
+			_, err := crawler.FetchResourcesWithContext(ctx, config)
+			return err
+		},
+	}
+
+	s.mux.HandleFunc("/crawls", s.handleCrawls)
+	s.mux.HandleFunc("/crawls/", s.handleCrawl)
+	if options.Config.Metrics != nil {
+		s.mux.Handle("/metrics", options.Config.Metrics)
+	}
+
+	for i := 0; i < options.Workers; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// 新しいジョブの受付を止め、待機中のジョブをキャンセルして実行中のジョブを待つ。ctxが終わったら実行中のジョブもキャンセルする
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+	s.cancelQueued()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.jobs.Range(func(_, value interface{}) bool {
+			value.(*Job).cancel()
+			return true
+		})
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (s *Server) worker() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.closed:
+			s.cancelQueued()
+			return
+		case job := <-s.queue:
+			// 停止中に取り出したジョブは実行しない
+			select {
+			case <-s.closed:
+				job.cancel()
+				job.finish(nil)
+			default:
+				s.run(job)
+			}
+		}
+	}
+}
+
+func (s *Server) cancelQueued() {
+	for {
+		select {
+		case job := <-s.queue:
+			job.cancel()
+			job.finish(nil)
+		default:
+			return
+		}
+	}
+}
+
+func (s *Server) run(job *Job) {
+	if !job.start() {
+		return
+	}
+
+	config := (&crawler.Config{}).Merge(s.options.Config).Merge(job.Request.config())
+	config.OnResource = job.add
+
+	log.Printf("start crawl %s", job.Id)
+	err := s.crawl(job.ctx, config)
+	job.finish(err)
+	log.Printf("finish crawl %s: %s", job.Id, job.info().Status)
+}
+
+// 保持期間を過ぎたものと、数の上限を超えた古いものから終わったジョブを消す
+func (s *Server) prune() {
+	var finished []JobInfo
+	s.jobs.Range(func(_, value interface{}) bool {
+		if info := value.(*Job).info(); info.Status.done() {
+			finished = append(finished, info)
+		}
+		return true
+	})
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].FinishedAt.Before(*finished[j].FinishedAt)
+	})
+
+	expired := time.Now().Add(-s.options.Retention)
+	for i, info := range finished {
+		if info.FinishedAt.Before(expired) || len(finished)-i > s.options.MaxFinished {
+			s.jobs.Delete(info.Id)
+		}
+	}
+}
+
+func (s *Server) handleCrawls(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.createCrawl(w, r)
+	case http.MethodGet:
+		jobs := []JobInfo{}
+		s.jobs.Range(func(_, value interface{}) bool {
+			jobs = append(jobs, value.(*Job).info())
+			return true
+		})
+		writeJson(w, http.StatusOK, jobs)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+	}
+}
+
+func (s *Server) createCrawl(w http.ResponseWriter, r *http.Request) {
+	request := CrawlRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %s", err))
+		return
+	}
+
+	if request.Username == "" && request.Hashtag == "" && request.Location == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("one of username, hashtag or location must be specified"))
+		return
+	}
+	if request.MaxConnections > s.options.MaxConnections {
+		request.MaxConnections = s.options.MaxConnections
+	}
+	if request.MaxConnections < 0 {
+		request.MaxConnections = 0
+	}
+
+	select {
+	case <-s.closed:
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("server is shutting down"))
+		return
+	default:
+	}
+
+	job := newJob(request)
+	select {
+	case s.queue <- job:
+	default:
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("crawl queue is full"))
+		return
+	}
+	s.prune()
+	s.jobs.Store(job.Id, job)
+
+	// 受付を確認してからキューに入れるまでの間に停止した場合
+	select {
+	case <-s.closed:
+		s.cancelQueued()
+	default:
+	}
+
+	w.Header().Set("Location", "/crawls/"+job.Id)
+	writeJson(w, http.StatusAccepted, job.info())
+}
+
+// /crawls/{id} と /crawls/{id}/resources
+func (s *Server) handleCrawl(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/crawls/"), "/"), "/")
+
+	value, ok := s.jobs.Load(segments[0])
+	if !ok || len(segments) > 2 || (len(segments) == 2 && segments[1] != "resources") {
+		writeError(w, http.StatusNotFound, fmt.Errorf("not found"))
+		return
+	}
+	job := value.(*Job)
+
+	switch {
+	case len(segments) == 2 && r.Method == http.MethodGet:
+		s.streamResources(w, r, job)
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		writeJson(w, http.StatusOK, job.info())
+	case len(segments) == 1 && r.Method == http.MethodDelete:
+		job.cancel()
+		// 待機中のジョブはワーカーに渡る前に終わらせる
+		job.finish(nil)
+		writeJson(w, http.StatusOK, job.info())
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+	}
+}
+
+// クロールが終わるまでJSON Linesでリソースを送り続ける
+func (s *Server) streamResources(w http.ResponseWriter, r *http.Request, job *Job) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	offset := 0
+	for {
+		resources, done, changed := job.resourcesFrom(offset)
+		for _, resource := range resources {
+			if err := encoder.Encode(resource); err != nil {
+				return
+			}
+		}
+		offset += len(resources)
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if done {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJson(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/kouheiszk/ig-crawler"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(options Options, crawl func(ctx context.Context, config *crawler.Config) error) (*Server, *httptest.Server) {
+	s := New(options)
+	s.crawl = crawl
+	return s, httptest.NewServer(s)
+}
+
+func createCrawl(t *testing.T, url string, body string) (int, JobInfo) {
+	response, err := http.Post(url+"/crawls", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	info := JobInfo{}
+	json.NewDecoder(response.Body).Decode(&info)
+	return response.StatusCode, info
+}
+
+func TestStreamResources(t *testing.T) {
+	s, ts := newTestServer(Options{}, func(ctx context.Context, config *crawler.Config) error {
+		for _, code := range []string{"a", "b", "c"} {
+			config.OnResource(crawler.Resource{Shortcode: code, Url: "https://example.com/" + config.Username})
+			time.Sleep(10 * time.Millisecond)
+		}
+		return nil
+	})
+	defer ts.Close()
+	defer s.Shutdown(context.Background())
+
+	status, info := createCrawl(t, ts.URL, `{"username":"kouheiszk"}`)
+	if status != http.StatusAccepted || info.Id == "" {
+		t.Fatalf("unexpected response %d %+v", status, info)
+	}
+
+	response, err := http.Get(ts.URL + "/crawls/" + info.Id + "/resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	var codes []string
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		resource := crawler.Resource{}
+		if err := json.Unmarshal(scanner.Bytes(), &resource); err != nil {
+			t.Fatal(err)
+		}
+		codes = append(codes, resource.Shortcode)
+	}
+	if strings.Join(codes, ",") != "a,b,c" {
+		t.Errorf("unexpected resources %v", codes)
+	}
+
+	response, err = http.Get(ts.URL + "/crawls/" + info.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	json.NewDecoder(response.Body).Decode(&info)
+	if info.Status != Succeeded || info.Resources != 3 {
+		t.Errorf("unexpected job %+v", info)
+	}
+}
+
+func TestCancelAndQueueLimit(t *testing.T) {
+	s, ts := newTestServer(Options{QueueSize: 1}, func(ctx context.Context, config *crawler.Config) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	defer ts.Close()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		s.Shutdown(ctx)
+	}()
+
+	_, running := createCrawl(t, ts.URL, `{"username":"running"}`)
+	for s.jobStatus(running.Id) != Running {
+		time.Sleep(time.Millisecond)
+	}
+	createCrawl(t, ts.URL, `{"username":"queued"}`)
+	if status, _ := createCrawl(t, ts.URL, `{"username":"rejected"}`); status != http.StatusServiceUnavailable {
+		t.Errorf("unexpected status %d", status)
+	}
+
+	request, _ := http.NewRequest(http.MethodDelete, ts.URL+"/crawls/"+running.Id, nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if status := s.jobStatus(running.Id); status != Canceled {
+		t.Errorf("unexpected status %s", status)
+	}
+}
+
+func TestPruneAndClamp(t *testing.T) {
+	s, ts := newTestServer(Options{MaxFinished: 2, Retention: time.Hour, MaxConnections: 2}, func(ctx context.Context, config *crawler.Config) error {
+		if config.MaxConnections > 2 {
+			t.Errorf("max_connections must be clamped, got %d", config.MaxConnections)
+		}
+		return nil
+	})
+	defer ts.Close()
+	defer s.Shutdown(context.Background())
+
+	var ids []string
+	for i := 0; i < 4; i++ {
+		_, info := createCrawl(t, ts.URL, `{"username":"kouheiszk","max_connections":100}`)
+		if info.Request.MaxConnections != 2 {
+			t.Errorf("unexpected request %+v", info.Request)
+		}
+		for s.jobStatus(info.Id) != Succeeded {
+			time.Sleep(time.Millisecond)
+		}
+		ids = append(ids, info.Id)
+	}
+
+	// 終わったジョブは新しい2つだけ残る
+	s.prune()
+	for i, id := range ids {
+		if _, ok := s.jobs.Load(id); ok != (i >= 2) {
+			t.Errorf("job %d: unexpected retention %v", i, ok)
+		}
+	}
+
+	s.options.Retention = time.Nanosecond
+	s.prune()
+	for _, id := range ids {
+		if _, ok := s.jobs.Load(id); ok {
+			t.Errorf("expired job %s must be removed", id)
+		}
+	}
+}
+
+func (s *Server) jobStatus(id string) JobStatus {
+	value, _ := s.jobs.Load(id)
+	return value.(*Job).info().Status
+}
@@ -0,0 +1,29 @@
+package ua
+
+type Device string
+
+const (
+	Desktop Device = "desktop"
+	Mobile  Device = "mobile"
+	Tablet  Device = "tablet"
+)
+
+// list.goの1件。data/useragents.jsonから生成する
+type Entry struct {
+	UserAgent string
+	Browser   Browser
+	Version   string
+	OS        Platform
+	Device    Device
+}
+
+var UserAgents = userAgents(Entries)
+
+func userAgents(entries []Entry) []string {
+	list := make([]string, len(entries))
+	for i, entry := range entries {
+		list[i] = entry.UserAgent
+	}
+
+	return list
+}
@@ -0,0 +1,3 @@
+package ua
+
+//go:generate go run ./internal/genlist -data data/useragents.json -o list.go
@@ -0,0 +1,148 @@
+// data/useragents.jsonから条件に合うものを選び、重複を除いてlist.goを書き出す
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type entry struct {
+	UserAgent string `json:"user_agent"`
+	Browser   string `json:"browser"`
+	Version   string `json:"version"`
+	OS        string `json:"os"`
+	Device    string `json:"device"`
+}
+
+type filter struct {
+	browsers   []string
+	oses       []string
+	devices    []string
+	minVersion int
+}
+
+func main() {
+	data := flag.String("data", "data/useragents.json", "JSON file of user agents with metadata.")
+	output := flag.String("o", "list.go", "Go file to write.")
+	browsers := flag.String("browser", "", "Comma separated browsers to keep, e.g. chrome,safari.")
+	oses := flag.String("os", "", "Comma separated operating systems to keep, e.g. windows,macos.")
+	devices := flag.String("device", "", "Comma separated device classes to keep: desktop, mobile, tablet.")
+	minVersion := flag.Int("min-version", 0, "Minimum major browser version to keep.")
+	flag.Parse()
+
+	bytes, err := ioutil.ReadFile(*data)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var entries []entry
+	if err = json.Unmarshal(bytes, &entries); err != nil {
+		log.Fatalln(fmt.Errorf("invalid data file \"%s\": %v", *data, err))
+	}
+
+	f := filter{
+		browsers:   splitList(*browsers),
+		oses:       splitList(*oses),
+		devices:    splitList(*devices),
+		minVersion: *minVersion,
+	}
+	source, err := generate(dedupe(f.apply(entries)), os.Args[1:])
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if err = ioutil.WriteFile(*output, source, 0644); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(strings.ToLower(item)); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+func (f *filter) apply(entries []entry) []entry {
+	var kept []entry
+	for _, e := range entries {
+		if !contains(f.browsers, e.Browser) || !contains(f.oses, e.OS) || !contains(f.devices, e.Device) {
+			continue
+		}
+		if f.minVersion > 0 && majorVersion(e.Version) < f.minVersion {
+			continue
+		}
+		kept = append(kept, e)
+	}
+
+	return kept
+}
+
+// 空のリストは全てに一致する
+func contains(list []string, s string) bool {
+	if len(list) == 0 {
+		return true
+	}
+
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 読めないバージョンは0として扱う
+func majorVersion(version string) int {
+	major, err := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	if err != nil {
+		return 0
+	}
+
+	return major
+}
+
+// 空白の違いだけのものも同じとみなし、最初に現れたものを残す
+func dedupe(entries []entry) []entry {
+	seen := map[string]bool{}
+	var unique []entry
+	for _, e := range entries {
+		key := strings.Join(strings.Fields(e.UserAgent), " ")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, e)
+	}
+
+	return unique
+}
+
+// 同じ条件で作り直せるよう、実行したコマンドを先頭に残す
+func generate(entries []entry, args []string) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	fmt.Fprintf(buffer, "// Code generated by %s; DO NOT EDIT.\n", strings.Join(append([]string{"genlist"}, args...), " "))
+	fmt.Fprintln(buffer)
+	fmt.Fprintln(buffer, "package ua")
+	fmt.Fprintln(buffer)
+	fmt.Fprintln(buffer, "var Entries = []Entry{")
+	for _, e := range entries {
+		fmt.Fprintf(buffer, "{UserAgent: %q, Browser: %q, Version: %q, OS: %q, Device: %q},\n", e.UserAgent, e.Browser, e.Version, e.OS, e.Device)
+	}
+	fmt.Fprintln(buffer, "}")
+
+	return format.Source(buffer.Bytes())
+}
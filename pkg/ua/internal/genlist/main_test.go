@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	entries := []entry{
+		{UserAgent: "a", Browser: "chrome", Version: "49.0.2623.47", OS: "windows", Device: "desktop"},
+		{UserAgent: "b", Browser: "chrome", Version: "30.0", OS: "android", Device: "tablet"},
+		{UserAgent: "c", Browser: "safari", Version: "9.1", OS: "macos", Device: "desktop"},
+		{UserAgent: "d", Browser: "opera", Version: "", OS: "windows", Device: "desktop"},
+	}
+
+	cases := []struct {
+		filter   filter
+		expected string
+	}{
+		{filter{}, "abcd"},
+		{filter{browsers: []string{"chrome", "safari"}}, "abc"},
+		{filter{oses: []string{"windows"}}, "ad"},
+		{filter{devices: []string{"desktop"}}, "acd"},
+		{filter{minVersion: 40}, "a"},
+		{filter{browsers: []string{"chrome"}, devices: []string{"mobile", "tablet"}}, "b"},
+	}
+
+	for _, c := range cases {
+		kept := ""
+		for _, e := range c.filter.apply(entries) {
+			kept += e.UserAgent
+		}
+		if kept != c.expected {
+			t.Errorf("%+v: expected %s, got %s", c.filter, c.expected, kept)
+		}
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	entries := []entry{
+		{UserAgent: "Mozilla/5.0 (X11)  Chrome/1", Version: "first"},
+		{UserAgent: "Mozilla/5.0 (X11) Chrome/1", Version: "second"},
+		{UserAgent: "Mozilla/5.0 (X11) Chrome/2"},
+	}
+
+	unique := dedupe(entries)
+	if len(unique) != 2 || unique[0].Version != "first" {
+		t.Errorf("unexpected entries %+v", unique)
+	}
+}
+
+// チェックインされたlist.goがデータファイルから再現できること
+func TestListIsUpToDate(t *testing.T) {
+	data, err := ioutil.ReadFile("../../data/useragents.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []entry
+	if err = json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := generate(dedupe((&filter{}).apply(entries)), []string{"-data", "data/useragents.json", "-o", "list.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := ioutil.ReadFile("../../list.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(source, list) {
+		t.Error("list.go is out of date, run go generate ./pkg/ua")
+	}
+}
@@ -0,0 +1,249 @@
+package ua
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	Opera     Browser = "opera"
+	Samsung   Browser = "samsung"
+	GoogleApp Browser = "google-app"
+	UCBrowser Browser = "ucbrowser"
+	CocCoc    Browser = "coccoc"
+	Mercury   Browser = "mercury"
+	IEMobile  Browser = "iemobile"
+)
+
+const (
+	ChromeOS     Platform = "chromeos"
+	FreeBSD      Platform = "freebsd"
+	Unix         Platform = "unix"
+	WindowsPhone Platform = "windows-phone"
+)
+
+type Engine string
+
+const (
+	Blink   Engine = "blink"
+	WebKit  Engine = "webkit"
+	Gecko   Engine = "gecko"
+	Trident Engine = "trident"
+)
+
+type Info struct {
+	Browser       Browser
+	Version       string
+	Engine        Engine
+	EngineVersion string
+	OS            Platform
+	Device        Device
+}
+
+// これより古いブラウザはInstagramに怪しまれるので使わない
+var MinimumVersions = map[Browser]int{
+	Chrome:  100,
+	Edge:    100,
+	Firefox: 100,
+	Safari:  15,
+	Opera:   86,
+	Samsung: 18,
+}
+
+const version = `([0-9][0-9A-Za-z.]*)`
+
+// 派生ブラウザはChromeやSafariのトークンも含むので、先に調べる
+var browserTokens = []struct {
+	browser Browser
+	regexp  *regexp.Regexp
+}{
+	{IEMobile, regexp.MustCompile(`IEMobile/` + version)},
+	{Opera, regexp.MustCompile(`OPR/` + version)},
+	{Samsung, regexp.MustCompile(`SamsungBrowser/` + version)},
+	{UCBrowser, regexp.MustCompile(`UCBrowser/` + version)},
+	{CocCoc, regexp.MustCompile(`coc_coc_browser/` + version)},
+	{GoogleApp, regexp.MustCompile(`GSA/` + version)},
+	{Mercury, regexp.MustCompile(`Mercury/` + version)},
+	{Edge, regexp.MustCompile(`Edg(?:e|A|iOS)?/` + version)},
+	{Firefox, regexp.MustCompile(`(?:Firefox|FxiOS)/` + version)},
+	{Chrome, regexp.MustCompile(`(?:Chrome|CriOS)/` + version)},
+	{Safari, regexp.MustCompile(`Version/` + version)},
+}
+
+var chromeToken = regexp.MustCompile(`Chrome/` + version)
+var webKitToken = regexp.MustCompile(`AppleWebKit/` + version)
+var geckoToken = regexp.MustCompile(`rv:` + version)
+var tridentToken = regexp.MustCompile(`Trident/` + version)
+var mobileToken = regexp.MustCompile(`\bMobile\b`)
+
+// ブラウザが分からないものはエラーにする
+func Parse(userAgent string) (*Info, error) {
+	info := &Info{}
+	for _, token := range browserTokens {
+		if match := token.regexp.FindStringSubmatch(userAgent); match != nil {
+			info.Browser = token.browser
+			info.Version = match[1]
+			break
+		}
+	}
+	if info.Browser == "" {
+		return nil, fmt.Errorf("unrecognized user agent \"%s\"", userAgent)
+	}
+
+	info.OS, info.Device = parsePlatform(userAgent)
+	info.Engine, info.EngineVersion = parseEngine(userAgent, info)
+
+	return info, nil
+}
+
+func parsePlatform(userAgent string) (Platform, Device) {
+	switch {
+	case strings.Contains(userAgent, "Windows Phone"):
+		return WindowsPhone, Mobile
+	case strings.Contains(userAgent, "iPad"):
+		return IOS, Tablet
+	case strings.Contains(userAgent, "iPhone") || strings.Contains(userAgent, "iPod"):
+		return IOS, Mobile
+	case strings.Contains(userAgent, "Android"):
+		if mobileToken.MatchString(userAgent) {
+			return Android, Mobile
+		}
+		return Android, Tablet
+	case strings.Contains(userAgent, "CrOS"):
+		return ChromeOS, Desktop
+	case strings.Contains(userAgent, "Windows"):
+		return Windows, Desktop
+	case strings.Contains(userAgent, "Macintosh"):
+		return MacOS, Desktop
+	case strings.Contains(userAgent, "FreeBSD"):
+		return FreeBSD, Desktop
+	case strings.Contains(userAgent, "Linux"):
+		return Linux, Desktop
+	default:
+		return Unix, Desktop
+	}
+}
+
+func parseEngine(userAgent string, info *Info) (Engine, string) {
+	if match := tridentToken.FindStringSubmatch(userAgent); match != nil {
+		return Trident, match[1]
+	}
+
+	webKit := webKitToken.FindStringSubmatch(userAgent)
+	// iOSのブラウザは全てWebKit
+	if info.OS == IOS && webKit != nil {
+		return WebKit, webKit[1]
+	}
+
+	if info.Browser == Firefox {
+		if match := geckoToken.FindStringSubmatch(userAgent); match != nil {
+			return Gecko, match[1]
+		}
+		return Gecko, ""
+	}
+
+	// Chrome 28からBlink
+	if match := chromeToken.FindStringSubmatch(userAgent); match != nil && majorVersion(match[1]) >= 28 {
+		return Blink, match[1]
+	}
+
+	if webKit != nil {
+		return WebKit, webKit[1]
+	}
+
+	return "", ""
+}
+
+func (i *Info) Major() int {
+	return majorVersion(i.Version)
+}
+
+func (i *Info) Outdated() bool {
+	return i.outdated() != ""
+}
+
+// 最低バージョンのない派生ブラウザは元になったChromiumかFirefoxのバージョンで判断し、それも分からなければ古いものとして扱う。古い場合はその理由を返す
+func (i *Info) outdated() string {
+	if minimum, ok := MinimumVersions[i.Browser]; ok {
+		if i.Major() < minimum {
+			return fmt.Sprintf("%s %s is older than %d", i.Browser, i.Version, minimum)
+		}
+		return ""
+	}
+
+	base := map[Engine]Browser{Blink: Chrome, Gecko: Firefox}[i.Engine]
+	if base == "" {
+		return fmt.Sprintf("%s %s is not based on a recent Chromium or Firefox", i.Browser, i.Version)
+	}
+	if minimum := MinimumVersions[base]; majorVersion(i.EngineVersion) < minimum {
+		return fmt.Sprintf("%s %s is based on %s %s, older than %d", i.Browser, i.Version, base, i.EngineVersion, minimum)
+	}
+
+	return ""
+}
+
+// 解析できないものと古すぎるものを拒否する
+func Validate(userAgent string) error {
+	info, err := Parse(userAgent)
+	if err != nil {
+		return err
+	}
+
+	if reason := info.outdated(); reason != "" {
+		return fmt.Errorf("user agent is outdated, %s: \"%s\"", reason, userAgent)
+	}
+
+	return nil
+}
+
+// 独自のUser-Agentに合うヘッダを送るためのプロファイルを作る
+func ProfileFor(userAgent string) (*Profile, error) {
+	for i := range Profiles {
+		if Profiles[i].UserAgent == userAgent {
+			return &Profiles[i], nil
+		}
+	}
+
+	if err := Validate(userAgent); err != nil {
+		return nil, err
+	}
+
+	info, _ := Parse(userAgent)
+	profile := &Profile{
+		Browser:        info.Browser,
+		Platform:       info.OS,
+		Version:        strconv.Itoa(info.Major()),
+		Mobile:         info.Device != Desktop,
+		UserAgent:      userAgent,
+		AcceptLanguage: "en-US,en;q=0.9",
+	}
+	if info.Browser == Firefox {
+		profile.AcceptLanguage = "en-US,en;q=0.5"
+	}
+
+	if info.Engine == Blink && info.OS != IOS {
+		chromium := strconv.Itoa(majorVersion(info.EngineVersion))
+		switch info.Browser {
+		case Chrome:
+			profile.ClientHints = `"Google Chrome";v="` + chromium + `", "Not?A_Brand";v="8", "Chromium";v="` + chromium + `"`
+		case Edge:
+			profile.ClientHints = `"Microsoft Edge";v="` + profile.Version + `", "Not?A_Brand";v="8", "Chromium";v="` + chromium + `"`
+		case Opera:
+			profile.ClientHints = `"Opera";v="` + profile.Version + `", "Not?A_Brand";v="8", "Chromium";v="` + chromium + `"`
+		}
+	}
+
+	return profile, nil
+}
+
+// 読めないバージョンは0として扱う
+func majorVersion(version string) int {
+	major, err := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	if err != nil {
+		return 0
+	}
+
+	return major
+}
@@ -0,0 +1,112 @@
+package ua
+
+import (
+	"testing"
+)
+
+// list.goのメタデータをそのまま期待値として使う
+func TestParseEntries(t *testing.T) {
+	for _, entry := range Entries {
+		info, err := Parse(entry.UserAgent)
+		if err != nil {
+			t.Errorf("%s: %v", entry.UserAgent, err)
+			continue
+		}
+		if info.Browser != entry.Browser || info.Version != entry.Version || info.OS != entry.OS || info.Device != entry.Device {
+			t.Errorf("%s: expected %s %s on %s %s, got %+v", entry.UserAgent, entry.Browser, entry.Version, entry.OS, entry.Device, info)
+		}
+		if info.Engine == "" {
+			t.Errorf("%s: engine is missing", entry.UserAgent)
+		}
+		// どれも古いので、クロールには使えない
+		if Validate(entry.UserAgent) == nil {
+			t.Errorf("%s: expected to be outdated", entry.UserAgent)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		userAgent string
+		expected  Info
+	}{
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36 Edg/141.0.0.0",
+			Info{Browser: Edge, Version: "141.0.0.0", Engine: Blink, EngineVersion: "141.0.0.0", OS: Windows, Device: Desktop},
+		},
+		{
+			"Mozilla/5.0 (X11; Linux x86_64; rv:144.0) Gecko/20100101 Firefox/144.0",
+			Info{Browser: Firefox, Version: "144.0", Engine: Gecko, EngineVersion: "144.0", OS: Linux, Device: Desktop},
+		},
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 18_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/141.0.7390.41 Mobile/15E148 Safari/604.1",
+			Info{Browser: Chrome, Version: "141.0.7390.41", Engine: WebKit, EngineVersion: "605.1.15", OS: IOS, Device: Mobile},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Mobile Safari/537.36",
+			Info{Browser: Chrome, Version: "141.0.0.0", Engine: Blink, EngineVersion: "141.0.0.0", OS: Android, Device: Mobile},
+		},
+	}
+
+	for _, c := range cases {
+		info, err := Parse(c.userAgent)
+		if err != nil {
+			t.Errorf("%s: %v", c.userAgent, err)
+			continue
+		}
+		if *info != c.expected {
+			t.Errorf("%s: expected %+v, got %+v", c.userAgent, c.expected, *info)
+		}
+	}
+
+	if _, err := Parse("curl/7.64.1"); err == nil {
+		t.Error("expected error for a non-browser user agent")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		userAgent string
+		valid     bool
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36 OPR/125.0.0.0", true},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) coc_coc_browser/150.0.0 Chrome/141.0.0.0 Safari/537.36", true},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) coc_coc_browser/54.2.133 Chrome/46.2.2490.133 Safari/537.36", false},
+		{"Mozilla/5.0 (Windows NT 5.1) AppleWebKit/535.1 (KHTML, like Gecko) Chrome/17.0.963.56 Safari/535.1 UCBrowser/9.0.2.299", false},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 18_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) GSA/388.0.811331708 Mobile/15E148 Safari/604.1", false},
+		{"Mozilla/5.0 (Mobile; Windows Phone 8.1; Android 4.0; ARM; Trident/7.0; Touch; rv:11.0; IEMobile/11.0; NOKIA; Lumia 635) like iPhone OS 7_0_3 Mac OS X AppleWebKit/537 (KHTML, like Gecko) Mobile Safari/537", false},
+	}
+
+	for _, c := range cases {
+		if err := Validate(c.userAgent); (err == nil) != c.valid {
+			t.Errorf("%s: unexpected error %v", c.userAgent, err)
+		}
+	}
+}
+
+func TestProfilesAreValid(t *testing.T) {
+	for _, profile := range Profiles {
+		info, err := Parse(profile.UserAgent)
+		if err != nil || info.Outdated() {
+			t.Errorf("%s: %v %+v", profile.UserAgent, err, info)
+			continue
+		}
+		if info.Browser != profile.Browser || info.OS != profile.Platform || (info.Device != Desktop) != profile.Mobile {
+			t.Errorf("%s: metadata does not match %+v", profile.UserAgent, info)
+		}
+	}
+}
+
+func TestProfileFor(t *testing.T) {
+	profile, err := ProfileFor("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/138.0.0.0 Safari/537.36 OPR/122.0.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Browser != Opera || profile.Platform != MacOS || profile.ClientHints != `"Opera";v="122", "Not?A_Brand";v="8", "Chromium";v="138"` {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+
+	if _, err = ProfileFor(UserAgents[0]); err == nil {
+		t.Error("expected error for an outdated user agent")
+	}
+}
@@ -0,0 +1,233 @@
+package ua
+
+import (
+	"fmt"
+)
+
+type Browser string
+
+const (
+	Chrome  Browser = "chrome"
+	Edge    Browser = "edge"
+	Firefox Browser = "firefox"
+	Safari  Browser = "safari"
+)
+
+type Platform string
+
+const (
+	Windows Platform = "windows"
+	MacOS   Platform = "macos"
+	Linux   Platform = "linux"
+	Android Platform = "android"
+	IOS     Platform = "ios"
+)
+
+// リクエストの種類によってAcceptとSec-Fetch-*が変わる
+type Destination string
+
+const (
+	DocumentDestination Destination = "document"
+	EmptyDestination    Destination = "empty" // fetchやXHR
+	ScriptDestination   Destination = "script"
+	ImageDestination    Destination = "image"
+	VideoDestination    Destination = "video"
+)
+
+// 1つのブラウザが送るヘッダの組み合わせ
+type Profile struct {
+	Browser        Browser
+	Platform       Platform
+	Version        string // メジャーバージョン
+	Mobile         bool
+	UserAgent      string
+	AcceptLanguage string
+	ClientHints    string  // Sec-CH-UA、Chromium系のブラウザだけが送る
+	Share          float64 // おおよそのシェア、Weightedでの選ばれやすさ
+}
+
+var Profiles = []Profile{
+	{
+		Browser:        Chrome,
+		Platform:       Windows,
+		Version:        "141",
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
+		AcceptLanguage: "en-US,en;q=0.9",
+		ClientHints:    `"Google Chrome";v="141", "Not?A_Brand";v="8", "Chromium";v="141"`,
+		Share:          0.36,
+	},
+	{
+		Browser:        Chrome,
+		Platform:       MacOS,
+		Version:        "141",
+		UserAgent:      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
+		AcceptLanguage: "en-US,en;q=0.9",
+		ClientHints:    `"Google Chrome";v="141", "Not?A_Brand";v="8", "Chromium";v="141"`,
+		Share:          0.08,
+	},
+	{
+		Browser:        Chrome,
+		Platform:       Linux,
+		Version:        "141",
+		UserAgent:      "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
+		AcceptLanguage: "en-US,en;q=0.9",
+		ClientHints:    `"Google Chrome";v="141", "Not?A_Brand";v="8", "Chromium";v="141"`,
+		Share:          0.02,
+	},
+	{
+		Browser:        Chrome,
+		Platform:       Android,
+		Version:        "141",
+		Mobile:         true,
+		UserAgent:      "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Mobile Safari/537.36",
+		AcceptLanguage: "en-US,en;q=0.9",
+		ClientHints:    `"Google Chrome";v="141", "Not?A_Brand";v="8", "Chromium";v="141"`,
+		Share:          0.24,
+	},
+	{
+		Browser:        Edge,
+		Platform:       Windows,
+		Version:        "141",
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36 Edg/141.0.0.0",
+		AcceptLanguage: "en-US,en;q=0.9",
+		ClientHints:    `"Microsoft Edge";v="141", "Not?A_Brand";v="8", "Chromium";v="141"`,
+		Share:          0.06,
+	},
+	{
+		Browser:        Firefox,
+		Platform:       Windows,
+		Version:        "144",
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:144.0) Gecko/20100101 Firefox/144.0",
+		AcceptLanguage: "en-US,en;q=0.5",
+		Share:          0.03,
+	},
+	{
+		Browser:        Firefox,
+		Platform:       MacOS,
+		Version:        "144",
+		UserAgent:      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:144.0) Gecko/20100101 Firefox/144.0",
+		AcceptLanguage: "en-US,en;q=0.5",
+		Share:          0.005,
+	},
+	{
+		Browser:        Firefox,
+		Platform:       Linux,
+		Version:        "144",
+		UserAgent:      "Mozilla/5.0 (X11; Linux x86_64; rv:144.0) Gecko/20100101 Firefox/144.0",
+		AcceptLanguage: "en-US,en;q=0.5",
+		Share:          0.01,
+	},
+	{
+		Browser:        Safari,
+		Platform:       MacOS,
+		Version:        "26",
+		UserAgent:      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/26.0 Safari/605.1.15",
+		AcceptLanguage: "en-US,en;q=0.9",
+		Share:          0.04,
+	},
+	{
+		Browser:        Safari,
+		Platform:       IOS,
+		Version:        "26",
+		Mobile:         true,
+		UserAgent:      "Mozilla/5.0 (iPhone; CPU iPhone OS 18_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/26.0 Mobile/15E148 Safari/604.1",
+		AcceptLanguage: "en-US,en;q=0.9",
+		Share:          0.15,
+	},
+}
+
+// 空文字はどれにでも一致する
+func SelectProfiles(browser Browser, platform Platform) []*Profile {
+	var profiles []*Profile
+	for i := range Profiles {
+		profile := &Profiles[i]
+		if (browser == "" || profile.Browser == browser) && (platform == "" || profile.Platform == platform) {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles
+}
+
+func RandomProfile(browser Browser, platform Platform) (*Profile, error) {
+	profiles := SelectProfiles(browser, platform)
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no user agent profile for browser \"%s\" on \"%s\"", browser, platform)
+	}
+
+	return profiles[random.Intn(len(profiles))], nil
+}
+
+// Accept-Encodingは付けない。付けるとnet/httpが自動で展開しなくなる
+func (p *Profile) Headers(dest Destination) map[string]string {
+	headers := map[string]string{
+		"user-agent":      p.UserAgent,
+		"accept":          p.accept(dest),
+		"accept-language": p.AcceptLanguage,
+		"sec-fetch-dest":  string(dest),
+	}
+
+	switch dest {
+	case DocumentDestination:
+		headers["sec-fetch-mode"] = "navigate"
+		headers["sec-fetch-site"] = "none"
+		headers["sec-fetch-user"] = "?1"
+		headers["upgrade-insecure-requests"] = "1"
+	case EmptyDestination:
+		headers["sec-fetch-mode"] = "cors"
+		headers["sec-fetch-site"] = "same-origin"
+	case ScriptDestination:
+		headers["sec-fetch-mode"] = "no-cors"
+		headers["sec-fetch-site"] = "same-origin"
+	default:
+		headers["sec-fetch-mode"] = "no-cors"
+		headers["sec-fetch-site"] = "cross-site"
+	}
+
+	if p.ClientHints != "" {
+		headers["sec-ch-ua"] = p.ClientHints
+		headers["sec-ch-ua-mobile"] = "?0"
+		if p.Mobile {
+			headers["sec-ch-ua-mobile"] = "?1"
+		}
+		headers["sec-ch-ua-platform"] = `"` + p.platformHint() + `"`
+	}
+
+	return headers
+}
+
+func (p *Profile) accept(dest Destination) string {
+	switch dest {
+	case DocumentDestination:
+		if p.ClientHints != "" {
+			return "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"
+		}
+		return "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+	case ImageDestination:
+		switch p.Browser {
+		case Firefox:
+			return "image/avif,image/webp,image/png,image/svg+xml,image/*;q=0.8,*/*;q=0.5"
+		case Safari:
+			return "image/webp,image/avif,image/jxl,image/heic,image/heic-sequence,video/*;q=0.8,image/png,image/svg+xml,image/*;q=0.8,*/*;q=0.5"
+		default:
+			return "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8"
+		}
+	default:
+		return "*/*"
+	}
+}
+
+func (p *Profile) platformHint() string {
+	switch p.Platform {
+	case MacOS:
+		return "macOS"
+	case IOS:
+		return "iOS"
+	case Windows:
+		return "Windows"
+	case Android:
+		return "Android"
+	default:
+		return "Linux"
+	}
+}
@@ -0,0 +1,67 @@
+package ua
+
+import (
+	"testing"
+)
+
+func TestSelectProfiles(t *testing.T) {
+	cases := []struct {
+		browser  Browser
+		platform Platform
+		count    int
+	}{
+		{"", "", len(Profiles)},
+		{Chrome, "", 4},
+		{"", Windows, 3},
+		{Safari, IOS, 1},
+		{Safari, Windows, 0},
+	}
+
+	for _, c := range cases {
+		profiles := SelectProfiles(c.browser, c.platform)
+		if len(profiles) != c.count {
+			t.Errorf("%s/%s: expected %d profiles, got %d", c.browser, c.platform, c.count, len(profiles))
+		}
+		for _, profile := range profiles {
+			if (c.browser != "" && profile.Browser != c.browser) || (c.platform != "" && profile.Platform != c.platform) {
+				t.Errorf("%s/%s: unexpected profile %+v", c.browser, c.platform, profile)
+			}
+		}
+	}
+
+	if _, err := RandomProfile(Safari, Windows); err == nil {
+		t.Error("expected error for unknown combination")
+	}
+}
+
+func TestProfileHeaders(t *testing.T) {
+	chrome := SelectProfiles(Chrome, Android)[0]
+	headers := chrome.Headers(EmptyDestination)
+	expected := map[string]string{
+		"user-agent":         chrome.UserAgent,
+		"accept":             "*/*",
+		"sec-ch-ua-mobile":   "?1",
+		"sec-ch-ua-platform": `"Android"`,
+		"sec-fetch-dest":     "empty",
+		"sec-fetch-mode":     "cors",
+		"sec-fetch-site":     "same-origin",
+	}
+	for key, value := range expected {
+		if headers[key] != value {
+			t.Errorf("%s: expected %q, got %q", key, value, headers[key])
+		}
+	}
+
+	// FirefoxとSafariはクライアントヒントを送らない
+	firefox := SelectProfiles(Firefox, Linux)[0]
+	headers = firefox.Headers(DocumentDestination)
+	if _, ok := headers["sec-ch-ua"]; ok {
+		t.Error("firefox must not send client hints")
+	}
+	if headers["sec-fetch-mode"] != "navigate" || headers["accept-language"] != "en-US,en;q=0.5" {
+		t.Errorf("unexpected headers %v", headers)
+	}
+	if _, ok := headers["accept-encoding"]; ok {
+		t.Error("accept-encoding disables transparent decompression")
+	}
+}
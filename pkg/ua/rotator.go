@@ -0,0 +1,144 @@
+package ua
+
+import (
+	"fmt"
+	"sync"
+)
+
+type Strategy string
+
+const (
+	Fixed      Strategy = "fixed"       // 最初に選んだものを使い続ける
+	PerCrawl   Strategy = "per-crawl"   // クロールごとにランダムに選ぶ
+	PerRequest Strategy = "per-request" // リクエストごとにランダムに選ぶ
+	RoundRobin Strategy = "round-robin" // リクエストごとに順番に選ぶ
+	Weighted   Strategy = "weighted"    // リクエストごとにシェアの重みで選ぶ
+)
+
+func ParseStrategy(s string) (Strategy, error) {
+	switch strategy := Strategy(s); strategy {
+	case Fixed, PerCrawl, PerRequest, RoundRobin, Weighted:
+		return strategy, nil
+	}
+
+	return "", fmt.Errorf("unknown user agent strategy \"%s\"", s)
+}
+
+// 複数のクロールから並行に使える
+type Rotator struct {
+	strategy Strategy
+	profiles []*Profile
+	random   *lockedRand
+
+	mutex  sync.Mutex
+	fixed  *Profile
+	next   int
+	sticky map[interface{}]*Profile
+}
+
+// 同じseedなら同じ順に選ぶ
+func NewRotator(strategy Strategy, profiles []*Profile, seed int64) (*Rotator, error) {
+	if _, err := ParseStrategy(string(strategy)); err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no user agent profiles to rotate")
+	}
+
+	r := &Rotator{
+		strategy: strategy,
+		profiles: profiles,
+		random:   newLockedRand(seed),
+		sticky:   map[interface{}]*Profile{},
+	}
+	r.fixed = profiles[r.random.Intn(len(profiles))]
+
+	return r, nil
+}
+
+func (r *Rotator) Strategy() Strategy {
+	return r.strategy
+}
+
+// クロールを始めるときに呼ぶ
+func (r *Rotator) Crawl() *Profile {
+	if r.strategy == Fixed {
+		return r.fixed
+	}
+
+	return r.pick()
+}
+
+// リクエストごとに呼ぶ。currentにはクロールで使っているものを渡す
+func (r *Rotator) Request(current *Profile) *Profile {
+	switch r.strategy {
+	case Fixed:
+		return r.fixed
+	case PerCrawl:
+		if current != nil {
+			return current
+		}
+		return r.Crawl()
+	default:
+		return r.pick()
+	}
+}
+
+// ログイン中のセッションでUser-Agentが変わると怪しまれるので、keyごとに最初に選んだものを使い続ける
+func (r *Rotator) Sticky(key interface{}) *Profile {
+	r.mutex.Lock()
+	profile, ok := r.sticky[key]
+	r.mutex.Unlock()
+	if ok {
+		return profile
+	}
+
+	profile = r.Crawl()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// 並行に選ばれた場合は先に登録されたものを使う
+	if existing, ok := r.sticky[key]; ok {
+		return existing
+	}
+	r.sticky[key] = profile
+
+	return profile
+}
+
+func (r *Rotator) pick() *Profile {
+	switch r.strategy {
+	case RoundRobin:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		profile := r.profiles[r.next%len(r.profiles)]
+		r.next++
+		return profile
+	case Weighted:
+		return r.weighted()
+	default:
+		return r.profiles[r.random.Intn(len(r.profiles))]
+	}
+}
+
+func (r *Rotator) weighted() *Profile {
+	var total float64
+	for _, profile := range r.profiles {
+		total += profile.Share
+	}
+	if total <= 0 {
+		return r.profiles[r.random.Intn(len(r.profiles))]
+	}
+
+	point := r.random.Float64() * total
+	for _, profile := range r.profiles {
+		point -= profile.Share
+		if point < 0 {
+			return profile
+		}
+	}
+
+	return r.profiles[len(r.profiles)-1]
+}
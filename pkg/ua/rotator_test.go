@@ -0,0 +1,85 @@
+package ua
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRotator(t *testing.T) {
+	profiles := SelectProfiles("", "")
+
+	fixed, _ := NewRotator(Fixed, profiles, 1)
+	first := fixed.Crawl()
+	for i := 0; i < 10; i++ {
+		if fixed.Crawl() != first || fixed.Request(nil) != first {
+			t.Fatal("fixed must not rotate")
+		}
+	}
+
+	perCrawl, _ := NewRotator(PerCrawl, profiles, 1)
+	current := perCrawl.Crawl()
+	if perCrawl.Request(current) != current {
+		t.Error("per-crawl must keep the profile of the crawl")
+	}
+
+	roundRobin, _ := NewRotator(RoundRobin, profiles[:3], 1)
+	for i := 0; i < 6; i++ {
+		if profile := roundRobin.Request(nil); profile != profiles[i%3] {
+			t.Errorf("%d: unexpected profile %s", i, profile.UserAgent)
+		}
+	}
+
+	// 同じシードなら同じ順になる
+	a, _ := NewRotator(PerRequest, profiles, 42)
+	b, _ := NewRotator(PerRequest, profiles, 42)
+	for i := 0; i < 10; i++ {
+		if a.Request(nil) != b.Request(nil) {
+			t.Fatal("rotators with the same seed diverged")
+		}
+	}
+
+	if _, err := NewRotator("sometimes", profiles, 1); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+	if _, err := NewRotator(Fixed, nil, 1); err == nil {
+		t.Error("expected error for no profiles")
+	}
+}
+
+func TestRotatorWeighted(t *testing.T) {
+	heavy := &Profile{UserAgent: "heavy", Share: 0.9}
+	light := &Profile{UserAgent: "light", Share: 0.1}
+	r, _ := NewRotator(Weighted, []*Profile{heavy, light}, 1)
+
+	counts := map[*Profile]int{}
+	for i := 0; i < 1000; i++ {
+		counts[r.Request(nil)]++
+	}
+	if counts[heavy] < 800 || counts[light] < 50 {
+		t.Errorf("unexpected distribution heavy=%d light=%d", counts[heavy], counts[light])
+	}
+}
+
+func TestRotatorSticky(t *testing.T) {
+	r, _ := NewRotator(PerRequest, SelectProfiles("", ""), 1)
+
+	session := &struct{}{}
+	profiles := make(chan *Profile, 20)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			profiles <- r.Sticky(session)
+		}()
+	}
+	wg.Wait()
+	close(profiles)
+
+	first := <-profiles
+	for profile := range profiles {
+		if profile != first {
+			t.Fatal("a session must keep one user agent")
+		}
+	}
+}
@@ -2,10 +2,37 @@ package ua
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 )
 
+// グローバルなmath/randを使い回したりシードし直したりしない。同じ秒に起動したクロールでも別の値になる
+var random = newLockedRand(time.Now().UnixNano())
+
+type lockedRand struct {
+	sync.Mutex
+	rand *rand.Rand
+}
+
+func newLockedRand(seed int64) *lockedRand {
+	return &lockedRand{rand: rand.New(rand.NewSource(seed))}
+}
+
+func (r *lockedRand) Intn(n int) int {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.rand.Intn(n)
+}
+
+func (r *lockedRand) Float64() float64 {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.rand.Float64()
+}
+
+// Deprecated: 一覧は古いブラウザばかりでValidateを通らない。RandomProfileを使う
 func RandomUserAgent() string {
-	rand.Seed(time.Now().Unix())
-	return UserAgents[rand.Intn(len(UserAgents))]
+	return UserAgents[random.Intn(len(UserAgents))]
 }
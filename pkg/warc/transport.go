@@ -0,0 +1,151 @@
+package warc
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"time"
+)
+
+type WriteError struct {
+	Err error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("couldn't write warc record: %s", e.Err)
+}
+
+const Redacted = "REDACTED"
+
+// セッションのクッキーやトークンを含むヘッダー
+var credentialHeaders = []string{"Cookie", "X-Csrftoken", "Authorization", "Proxy-Authorization"}
+
+// 通信内容をrequest、response、metadataレコードとして記録するRoundTripper
+type Transport struct {
+	Writer *Writer
+	Base   http.RoundTripper
+
+	// 有効にするとクッキーやトークンを伏せずにそのまま記録する
+	RecordCredentials bool
+}
+
+func (t *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	dumped := request
+	if !t.RecordCredentials {
+		dumped = redactRequest(request)
+	}
+	requestBlock, err := httputil.DumpRequestOut(dumped, true)
+	// DumpRequestOutは読んだボディを渡したリクエストに戻す
+	request.Body = dumped.Body
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	response, err := base.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	responseRecord := &Record{
+		Type:        ResponseRecord,
+		Date:        start,
+		TargetUri:   request.URL.String(),
+		ContentType: "application/http;msgtype=response",
+		Block:       dumpResponse(response, body, t.RecordCredentials),
+		Payload:     body,
+	}
+	if err = t.Writer.Write(responseRecord); err != nil {
+		return nil, &WriteError{err}
+	}
+
+	records := []*Record{
+		{
+			Type:        RequestRecord,
+			Date:        start,
+			TargetUri:   request.URL.String(),
+			ContentType: "application/http;msgtype=request",
+			Fields:      map[string]string{"WARC-Concurrent-To": responseRecord.Id},
+			Block:       requestBlock,
+		},
+		{
+			Type:        MetadataRecord,
+			Date:        start,
+			TargetUri:   request.URL.String(),
+			ContentType: "application/warc-fields",
+			Fields:      map[string]string{"WARC-Concurrent-To": responseRecord.Id},
+			Block:       []byte(fmt.Sprintf("fetchTimeMs: %d\r\n", time.Since(start)/time.Millisecond)),
+		},
+	}
+	for _, record := range records {
+		if err = t.Writer.Write(record); err != nil {
+			return nil, &WriteError{err}
+		}
+	}
+
+	return response, nil
+}
+
+// net/httpが展開したgzipやchunkedの転送を除いて、実際のボディに合わせたヘッダーで書き出す
+func dumpResponse(response *http.Response, body []byte, recordCredentials bool) []byte {
+	buffer := bytes.Buffer{}
+	fmt.Fprintf(&buffer, "HTTP/%d.%d %s\r\n", response.ProtoMajor, response.ProtoMinor, response.Status)
+
+	header := cloneHeader(response.Header)
+	header.Del("Transfer-Encoding")
+	if response.Uncompressed {
+		header.Del("Content-Encoding")
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	if !recordCredentials && len(header["Set-Cookie"]) > 0 {
+		cookies := make([]string, 0, len(header["Set-Cookie"]))
+		for _, cookie := range response.Cookies() {
+			cookie.Value = Redacted
+			cookies = append(cookies, cookie.String())
+		}
+		header["Set-Cookie"] = cookies
+	}
+	header.Write(&buffer)
+
+	buffer.WriteString("\r\n")
+	buffer.Write(body)
+
+	return buffer.Bytes()
+}
+
+func redactRequest(request *http.Request) *http.Request {
+	redacted := new(http.Request)
+	*redacted = *request
+	redacted.Header = cloneHeader(request.Header)
+	for _, name := range credentialHeaders {
+		if redacted.Header.Get(name) != "" {
+			redacted.Header.Set(name, Redacted)
+		}
+	}
+
+	return redacted
+}
+
+func cloneHeader(header http.Header) http.Header {
+	clone := http.Header{}
+	for key, values := range header {
+		clone[key] = append([]string(nil), values...)
+	}
+
+	return clone
+}
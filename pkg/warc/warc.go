@@ -0,0 +1,233 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const Version = "WARC/1.1"
+const DefaultMaxSize = 1 << 30
+
+const (
+	WarcinfoRecord = "warcinfo"
+	RequestRecord  = "request"
+	ResponseRecord = "response"
+	MetadataRecord = "metadata"
+)
+
+type Options struct {
+	MaxSize  int64 // ファイルをローテートするサイズ、0ならDefaultMaxSize
+	Gzip     bool  // レコードごとにgzipのメンバーとして圧縮する
+	Software string
+}
+
+type Record struct {
+	Type        string
+	Id          string
+	Date        time.Time
+	TargetUri   string
+	ContentType string
+	Fields      map[string]string // その他のWARCヘッダー
+	Block       []byte
+	Payload     []byte // WARC-Payload-Digestの対象、nilなら付けない
+}
+
+type Writer struct {
+	sync.Mutex
+	dir        string
+	prefix     string
+	options    Options
+	file       *os.File
+	size       int64
+	serial     int
+	warcinfoId string
+}
+
+func NewWriter(dir string, prefix string, options Options) (*Writer, error) {
+	if options.MaxSize == 0 {
+		options.MaxSize = DefaultMaxSize
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Writer{dir: dir, prefix: prefix, options: options}, nil
+}
+
+func (w *Writer) Write(record *Record) error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.file == nil || w.size >= w.options.MaxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if record.Fields == nil {
+		record.Fields = map[string]string{}
+	}
+	record.Fields["WARC-Warcinfo-ID"] = w.warcinfoId
+
+	return w.write(record)
+}
+
+func (w *Writer) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+
+	return err
+}
+
+// ファイルごとに先頭にwarcinfoレコードを書く
+func (w *Writer) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	name := fmt.Sprintf("%s-%s-%05d.warc", w.prefix, time.Now().UTC().Format("20060102150405"), w.serial)
+	if w.options.Gzip {
+		name += ".gz"
+	}
+	w.serial++
+
+	file, err := os.OpenFile(filepath.Join(w.dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	w.file = file
+	w.size = 0
+
+	info := bytes.Buffer{}
+	software := w.options.Software
+	if software == "" {
+		software = "ig-crawler"
+	}
+	fmt.Fprintf(&info, "software: %s\r\nformat: WARC File Format 1.1\r\nconformsTo: http://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/\r\n", software)
+
+	record := &Record{
+		Type:        WarcinfoRecord,
+		ContentType: "application/warc-fields",
+		Fields:      map[string]string{"WARC-Filename": name},
+		Block:       info.Bytes(),
+	}
+	if err = w.write(record); err != nil {
+		return err
+	}
+	w.warcinfoId = record.Id
+
+	return nil
+}
+
+func (w *Writer) write(record *Record) error {
+	if record.Id == "" {
+		record.Id = NewRecordId()
+	}
+	if record.Date.IsZero() {
+		record.Date = time.Now()
+	}
+
+	buffer := bytes.Buffer{}
+	buffer.WriteString(Version + "\r\n")
+	writeField(&buffer, "WARC-Type", record.Type)
+	writeField(&buffer, "WARC-Record-ID", record.Id)
+	writeField(&buffer, "WARC-Date", record.Date.UTC().Format("2006-01-02T15:04:05.000000Z"))
+	if record.TargetUri != "" {
+		writeField(&buffer, "WARC-Target-URI", record.TargetUri)
+	}
+
+	keys := make([]string, 0, len(record.Fields))
+	for key := range record.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if record.Fields[key] != "" {
+			writeField(&buffer, key, record.Fields[key])
+		}
+	}
+
+	if record.ContentType != "" {
+		writeField(&buffer, "Content-Type", record.ContentType)
+	}
+	writeField(&buffer, "WARC-Block-Digest", Digest(record.Block))
+	if record.Payload != nil {
+		writeField(&buffer, "WARC-Payload-Digest", Digest(record.Payload))
+	}
+	writeField(&buffer, "Content-Length", strconv.Itoa(len(record.Block)))
+	buffer.WriteString("\r\n")
+	buffer.Write(record.Block)
+	buffer.WriteString("\r\n\r\n")
+
+	var writer io.Writer = w.file
+	var zipper *gzip.Writer
+	counter := &countWriter{w: writer}
+	if w.options.Gzip {
+		zipper = gzip.NewWriter(counter)
+		writer = zipper
+	} else {
+		writer = counter
+	}
+
+	if _, err := writer.Write(buffer.Bytes()); err != nil {
+		return err
+	}
+	if zipper != nil {
+		if err := zipper.Close(); err != nil {
+			return err
+		}
+	}
+	w.size += counter.n
+
+	return nil
+}
+
+func writeField(buffer *bytes.Buffer, name string, value string) {
+	buffer.WriteString(name + ": " + value + "\r\n")
+}
+
+func Digest(b []byte) string {
+	sum := sha1.Sum(b)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+func NewRecordId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
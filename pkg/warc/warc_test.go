@@ -0,0 +1,117 @@
+package warc
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		http.SetCookie(w, &http.Cookie{Name: "sessionid", Value: "rotated", Path: "/"})
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "warc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writer, err := NewWriter(dir, "test", Options{Gzip: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: &Transport{Writer: writer}}
+	request, _ := http.NewRequest("GET", server.URL+"/page", nil)
+	request.Header.Set("Cookie", "sessionid=secret")
+	request.Header.Set("X-Csrftoken", "csrfvalue")
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if string(body) != "hello" {
+		t.Errorf("unexpected body %s", body)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.warc.gz"))
+	if len(files) != 1 {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	file, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// 連結されたgzipメンバーは続けて読める
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "WARC-Type: ") {
+			types = append(types, strings.TrimPrefix(scanner.Text(), "WARC-Type: "))
+		}
+	}
+	if strings.Join(types, ",") != "warcinfo,response,request,metadata" {
+		t.Errorf("unexpected records %v", types)
+	}
+	for _, secret := range []string{"secret", "csrfvalue", "rotated"} {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("credential %q is recorded", secret)
+		}
+	}
+	if response.Cookies()[0].Value != "rotated" {
+		t.Error("response cookies must not be redacted for the client")
+	}
+	if !strings.Contains(string(content), "WARC-Payload-Digest: "+Digest([]byte("hello"))) {
+		t.Error("payload digest not found")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "warc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writer, err := NewWriter(dir, "test", Options{MaxSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err = writer.Write(&Record{Type: MetadataRecord, Block: []byte("data")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writer.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.warc"))
+	if len(files) != 3 {
+		t.Errorf("unexpected files %v", files)
+	}
+}
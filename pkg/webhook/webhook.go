@@ -0,0 +1,191 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/kouheiszk/ig-crawler"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const SignatureHeader = "X-Crawler-Signature-256"
+const EventHeader = "X-Crawler-Event"
+const DefaultMaxAttempts = 5
+const DefaultBackoff = time.Second
+const RequestTimeout = 30 * time.Second
+
+const (
+	NewPostsEvent    = "crawl.new_posts"
+	CrawlFailedEvent = "crawl.failed"
+)
+
+type Endpoint struct {
+	Url    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+}
+
+type Options struct {
+	Endpoints   []Endpoint
+	DeadLetter  string        // 配信できなかったペイロードを追記するJSON Linesファイル
+	MaxAttempts int           // 0ならDefaultMaxAttempts
+	Backoff     time.Duration // 最初の再試行までの時間、以降は倍にしていく
+	Client      *http.Client
+}
+
+type Payload struct {
+	Event      string             `json:"event"`
+	Job        string             `json:"job,omitempty"`
+	Username   string             `json:"username"`
+	Status     string             `json:"status"`
+	Error      string             `json:"error,omitempty"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt time.Time          `json:"finished_at"`
+	Resources  []crawler.Resource `json:"resources"`
+	Posts      []crawler.Post     `json:"posts"`
+}
+
+type DeadLetter struct {
+	Url      string          `json:"url"`
+	Event    string          `json:"event"`
+	Payload  json.RawMessage `json:"payload"`
+	Error    string          `json:"error"`
+	Attempts int             `json:"attempts"`
+	FailedAt time.Time       `json:"failed_at"`
+}
+
+type Notifier struct {
+	options Options
+	mutex   sync.Mutex
+}
+
+func New(options Options) *Notifier {
+	if options.MaxAttempts == 0 {
+		options.MaxAttempts = DefaultMaxAttempts
+	}
+	if options.Backoff == 0 {
+		options.Backoff = DefaultBackoff
+	}
+	if options.Client == nil {
+		options.Client = &http.Client{Timeout: RequestTimeout}
+	}
+
+	return &Notifier{options: options}
+}
+
+// 全てのエンドポイントに配信する。配信できなかったものはデッドレターに書き、そのエラーを返す
+func (n *Notifier) Notify(ctx context.Context, payload *Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, endpoint := range n.options.Endpoints {
+		attempts, err := n.deliver(ctx, endpoint, payload.Event, body)
+		if err == nil {
+			continue
+		}
+
+		log.Printf("couldn't deliver webhook to %s: %v", endpoint.Url, err)
+		if deadErr := n.writeDeadLetter(DeadLetter{
+			Url:      endpoint.Url,
+			Event:    payload.Event,
+			Payload:  body,
+			Error:    err.Error(),
+			Attempts: attempts,
+			FailedAt: time.Now(),
+		}); deadErr != nil {
+			log.Print(deadErr)
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func (n *Notifier) deliver(ctx context.Context, endpoint Endpoint, event string, body []byte) (int, error) {
+	backoff := n.options.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = n.post(ctx, endpoint, event, body); err == nil {
+			return attempt, nil
+		}
+
+		if attempt >= n.options.MaxAttempts {
+			return attempt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempt, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, endpoint Endpoint, event string, body []byte) error {
+	request, err := http.NewRequest("POST", endpoint.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, event)
+	if endpoint.Secret != "" {
+		request.Header.Set(SignatureHeader, Sign(endpoint.Secret, body))
+	}
+
+	response, err := n.options.Client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+
+	return nil
+}
+
+func (n *Notifier) writeDeadLetter(letter DeadLetter) error {
+	if n.options.DeadLetter == "" {
+		return nil
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	file, err := os.OpenFile(n.options.DeadLetter, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err = json.NewEncoder(file).Encode(letter); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// 受信側はボディのHMAC-SHA256をhmac.Equalで比較して検証する
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	var received Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get(SignatureHeader) != Sign("secret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.Unmarshal(body, &received)
+	}))
+	defer server.Close()
+
+	notifier := New(Options{Endpoints: []Endpoint{{Url: server.URL, Secret: "secret"}}})
+	err := notifier.Notify(context.Background(), &Payload{Event: NewPostsEvent, Username: "kouheiszk", Status: "succeeded"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received.Username != "kouheiszk" || received.Event != NewPostsEvent {
+		t.Errorf("unexpected payload %+v", received)
+	}
+}
+
+func TestNotifyDeadLetter(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	notifier := New(Options{
+		Endpoints:   []Endpoint{{Url: server.URL}},
+		DeadLetter:  filepath.Join(dir, "dead.jsonl"),
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+	})
+	if err = notifier.Notify(context.Background(), &Payload{Event: CrawlFailedEvent, Username: "kouheiszk"}); err == nil {
+		t.Error("expected delivery error")
+	}
+	if requests != 3 {
+		t.Errorf("unexpected attempts %d", requests)
+	}
+
+	file, err := os.Open(filepath.Join(dir, "dead.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	letter := DeadLetter{}
+	if !scanner.Scan() || json.Unmarshal(scanner.Bytes(), &letter) != nil {
+		t.Fatal("dead letter not written")
+	}
+	if letter.Url != server.URL || letter.Attempts != 3 || letter.Event != CrawlFailedEvent {
+		t.Errorf("unexpected dead letter %+v", letter)
+	}
+}
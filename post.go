@@ -0,0 +1,213 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var shortcodeRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type Post struct {
+	Id           string      `json:"id"`
+	Shortcode    string      `json:"shortcode"`
+	Typename     string      `json:"typename"`
+	Url          string      `json:"url"`
+	Caption      string      `json:"caption"`
+	Timestamp    int32       `json:"timestamp"`
+	IsVideo      bool        `json:"is_video"`
+	DisplayUrl   string      `json:"display_url"`
+	VideoUrl     string      `json:"video_url,omitempty"`
+	LikeCount    int         `json:"like_count"`
+	CommentCount int         `json:"comment_count"`
+	OwnerId      string      `json:"owner_id"`
+	Owner        string      `json:"owner"`
+	Children     []PostChild `json:"children,omitempty"`
+	Comments     []Comment   `json:"comments,omitempty"`
+}
+
+type PostChild struct {
+	Id         string `json:"id"`
+	Typename   string `json:"typename"`
+	IsVideo    bool   `json:"is_video"`
+	DisplayUrl string `json:"display_url"`
+	VideoUrl   string `json:"video_url,omitempty"`
+}
+
+func (c *PostChild) MediaUrl() string {
+	if c.IsVideo {
+		return c.VideoUrl
+	}
+
+	return c.DisplayUrl
+}
+
+func FetchPost(ctx context.Context, config *Config, shortcodeOrUrl string) (*Post, error) {
+	return NewCrawler(config).FetchPost(ctx, shortcodeOrUrl)
+}
+
+func (c *Crawler) FetchPost(ctx context.Context, shortcodeOrUrl string) (*Post, error) {
+	shortcode, err := parseShortcode(shortcodeOrUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := c.fetchPost(ctx, postUrl(shortcode))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.config.Comments {
+		post.Comments, err = c.fetchComments(ctx, post.Shortcode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return post, nil
+}
+
+func (c *Crawler) fetchPost(ctx context.Context, postUrl string) (*Post, error) {
+	response, err := c.fetch(ctx, postUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonString, err := extractSharedDataJsonString(response)
+	if err != nil {
+		return nil, err
+	}
+
+	pageJson, err := decodeSharedData(jsonString)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid post page json \"%s\"", jsonString)
+	}
+
+	// prepareを経ずに投稿だけを取得した場合、コメントのクエリの署名に使う
+	if c.rhxGis == "" {
+		c.rhxGis = pageJson.RhxGis
+	}
+
+	media, err := pageJson.shortcodeMedia()
+	if err != nil {
+		return nil, err
+	}
+
+	return newPost(media), nil
+}
+
+func newPost(media *shortcodeMediaJsonType) *Post {
+	post := &Post{
+		Id:           media.Id,
+		Shortcode:    media.Shortcode,
+		Typename:     media.Typename,
+		Url:          postUrl(media.Shortcode),
+		Caption:      media.EdgeMediaToCaption.text(),
+		Timestamp:    media.Timestamp,
+		IsVideo:      media.IsVideo,
+		DisplayUrl:   media.DisplaySrc,
+		VideoUrl:     media.VideoUrl,
+		LikeCount:    media.EdgeMediaPreviewLike.Count,
+		CommentCount: media.EdgeMediaToComment.Count,
+		OwnerId:      media.Owner.Id,
+		Owner:        media.Owner.Username,
+	}
+
+	for _, element := range media.EdgeSidecarToChildren.Edges {
+		post.Children = append(post.Children, PostChild{
+			Id:         element.Node.Id,
+			Typename:   element.Node.Typename,
+			IsVideo:    element.Node.IsVideo,
+			DisplayUrl: element.Node.DisplaySrc,
+			VideoUrl:   element.Node.VideoUrl,
+		})
+	}
+
+	return post
+}
+
+func newPostFromNode(node *mediaNodeJsonType) *Post {
+	return &Post{
+		Id:           node.Id,
+		Shortcode:    node.Code,
+		Typename:     node.Typename,
+		Url:          postUrl(node.Code),
+		Caption:      node.EdgeMediaToCaption.text(),
+		Timestamp:    node.Timestamp,
+		IsVideo:      node.IsVideo,
+		DisplayUrl:   node.DisplaySrc,
+		LikeCount:    node.EdgeMediaPreviewLike.Count,
+		CommentCount: node.EdgeMediaToComment.Count,
+		OwnerId:      node.Owner.Id,
+		Owner:        node.Owner.Username,
+	}
+}
+
+func (c *captionJsonType) text() string {
+	if len(c.Edges) == 0 {
+		return ""
+	}
+
+	return c.Edges[0].Node.Text
+}
+
+// 投稿ページから取得した詳細で一覧の情報を上書きする
+func (s *ResourceStore) addPost(post *Post) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.posts[post.Shortcode] = post
+}
+
+func (s *ResourceStore) sortedPosts() []Post {
+	s.Lock()
+	defer s.Unlock()
+
+	posts := make([]Post, 0, len(s.posts))
+	for shortcode, post := range s.posts {
+		p := *post
+		if comments, ok := s.comments[shortcode]; ok {
+			p.Comments = comments
+		}
+		posts = append(posts, p)
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].Timestamp > posts[j].Timestamp
+	})
+
+	return posts
+}
+
+func postUrl(shortcode string) string {
+	return "https://www.instagram.com/p/" + shortcode + "/"
+}
+
+// "https://www.instagram.com/p/xxx/" や "/tv/xxx"、ショートコードそのものを受け付ける
+func parseShortcode(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if shortcodeRegexp.MatchString(s) {
+		return s, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid post url \"%s\"", s)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		switch segments[i] {
+		case "p", "tv", "reel":
+			if shortcodeRegexp.MatchString(segments[i+1]) {
+				return segments[i+1], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("couldn't find shortcode in \"%s\"", s)
+}
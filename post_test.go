@@ -0,0 +1,28 @@
+package crawler
+
+import "testing"
+
+func TestParseShortcode(t *testing.T) {
+	cases := map[string]string{
+		"BrEWmLxnL0T": "BrEWmLxnL0T",
+		"https://www.instagram.com/p/BrEWmLxnL0T/":            "BrEWmLxnL0T",
+		"https://www.instagram.com/p/BrEWmLxnL0T/?taken-by=x": "BrEWmLxnL0T",
+		"https://instagram.com/tv/Bq-8e_ThuRM":                "Bq-8e_ThuRM",
+		"https://www.instagram.com/kouheiszk/p/BrEWmLxnL0T/":  "BrEWmLxnL0T",
+	}
+
+	for input, expected := range cases {
+		shortcode, err := parseShortcode(input)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", input, err)
+			continue
+		}
+		if shortcode != expected {
+			t.Errorf("%s: got %q, want %q", input, shortcode, expected)
+		}
+	}
+
+	if _, err := parseShortcode("https://www.instagram.com/kouheiszk/"); err == nil {
+		t.Error("expected error for profile url")
+	}
+}
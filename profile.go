@@ -0,0 +1,56 @@
+package crawler
+
+import "context"
+
+type Profile struct {
+	Id                string `json:"id"`
+	Username          string `json:"username"`
+	FullName          string `json:"full_name"`
+	Biography         string `json:"biography"`
+	ExternalUrl       string `json:"external_url"`
+	FollowerCount     int    `json:"follower_count"`
+	FollowingCount    int    `json:"following_count"`
+	PostCount         int    `json:"post_count"`
+	IsVerified        bool   `json:"is_verified"`
+	IsPrivate         bool   `json:"is_private"`
+	IsBusinessAccount bool   `json:"is_business_account"`
+	BusinessCategory  string `json:"business_category,omitempty"`
+	HighlightCount    int    `json:"highlight_count"`
+	ProfilePicUrl     string `json:"profile_pic_url"`
+	ProfilePicUrlHd   string `json:"profile_pic_url_hd"`
+}
+
+func FetchProfile(config *Config) (*Profile, error) {
+	return FetchProfileWithContext(context.Background(), config)
+}
+
+// 非公開アカウントでもプロフィールは取得できる
+func FetchProfileWithContext(ctx context.Context, config *Config) (*Profile, error) {
+	crawler := NewCrawler(config)
+
+	if _, _, err := crawler.loadProfile(ctx); err != nil {
+		return nil, err
+	}
+
+	return newProfile(crawler.user), nil
+}
+
+func newProfile(user *userJsonType) *Profile {
+	return &Profile{
+		Id:                user.Id,
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Biography:         user.Biography,
+		ExternalUrl:       user.ExternalUrl,
+		FollowerCount:     user.EdgeFollowedBy.Count,
+		FollowingCount:    user.EdgeFollow.Count,
+		PostCount:         user.Media.Count,
+		IsVerified:        user.IsVerified,
+		IsPrivate:         user.IsPrivate,
+		IsBusinessAccount: user.IsBusinessAccount,
+		BusinessCategory:  user.BusinessCategoryName,
+		HighlightCount:    user.HighlightReelCount,
+		ProfilePicUrl:     user.ProfilePicUrlSmall,
+		ProfilePicUrlHd:   user.ProfilePicUrl,
+	}
+}
@@ -0,0 +1,53 @@
+package crawler
+
+import (
+	"time"
+)
+
+type ProgressKind string
+
+const (
+	ProfileLoaded   ProgressKind = "profile_loaded"
+	PageFetched     ProgressKind = "page_fetched"
+	GalleryExpanded ProgressKind = "gallery_expanded"
+	ResourceEmitted ProgressKind = "resource_emitted"
+	RetryScheduled  ProgressKind = "retry_scheduled"
+)
+
+type ProgressEvent struct {
+	Kind      ProgressKind
+	Target    string        // ユーザー名、"#"+ハッシュタグ、"location/"+id
+	Tab       string        // ProfileLoaded、PageFetched
+	Total     int           // ProfileLoaded: 投稿の総数
+	Posts     int           // PageFetched: このページで新しく見つかった投稿の数
+	Shortcode string        // GalleryExpanded
+	Children  int           // GalleryExpanded
+	Resource  *Resource     // ResourceEmitted
+	Url       string        // RetryScheduled
+	Reason    string        // RetryScheduled: "throttled" か "connection"
+	Delay     time.Duration // RetryScheduled
+}
+
+// クロール中の進捗を受け取る。Reportは並行には呼ばれない
+type ProgressReporter interface {
+	Report(event ProgressEvent)
+}
+
+type ProgressReporterFunc func(event ProgressEvent)
+
+func (f ProgressReporterFunc) Report(event ProgressEvent) {
+	f(event)
+}
+
+func (c *Crawler) report(event ProgressEvent) {
+	if c.config.Progress == nil {
+		return
+	}
+
+	event.Target = c.target()
+
+	c.progressMutex.Lock()
+	defer c.progressMutex.Unlock()
+
+	c.config.Progress.Report(event)
+}
@@ -0,0 +1,36 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestProgressEvents(t *testing.T) {
+	var events []ProgressEvent
+	crawler := NewCrawler(&Config{
+		Username: "kouheiszk",
+		Progress: ProgressReporterFunc(func(event ProgressEvent) {
+			events = append(events, event)
+		}),
+	})
+
+	media := mediaJsonType{}
+	json.Unmarshal([]byte(`{"count": 3, "edges": [
+		{"node": {"__typename": "GraphImage", "shortcode": "a", "taken_at_timestamp": 2}},
+		{"node": {"__typename": "GraphImage", "shortcode": "a", "taken_at_timestamp": 2}},
+		{"node": {"__typename": "GraphImage", "shortcode": "b", "taken_at_timestamp": 1}}
+	]}`), &media)
+	crawler.handleMedia(context.Background(), media, timelinePage)
+	crawler.handleResource(context.Background(), <-crawler.resourceChan)
+
+	if len(events) != 2 {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	if events[0].Kind != PageFetched || events[0].Posts != 2 || events[0].Tab != "timeline" || events[0].Target != "kouheiszk" {
+		t.Errorf("unexpected page event %+v", events[0])
+	}
+	if events[1].Kind != ResourceEmitted || events[1].Resource.Shortcode != "a" {
+		t.Errorf("unexpected resource event %+v", events[1])
+	}
+}
@@ -4,4 +4,12 @@ type Resource struct {
 	Url       string `json:"url"`
 	Timestamp int32  `json:"timestamp"`
 	IsVideo   bool   `json:"is_video"`
+	Shortcode string `json:"shortcode,omitempty"`
+	Tab       string `json:"tab,omitempty"`
+	ViewCount int    `json:"view_count,omitempty"`
+	PlayCount int    `json:"play_count,omitempty"`
+
+	HighlightId    string `json:"highlight_id,omitempty"`
+	HighlightTitle string `json:"highlight_title,omitempty"`
+	ExpiresAt      int32  `json:"expires_at,omitempty"`
 }
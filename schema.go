@@ -0,0 +1,128 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const schemaSnippetLength = 200
+
+// ログインやチャレンジなど、期待したページの代わりに返される中間ページ
+var interstitialPages = []struct {
+	key  string
+	name string
+}{
+	{"LoginAndSignupPage", "login"},
+	{"Challenge", "challenge"},
+	{"ConsentPage", "consent"},
+	{"GDPRConsentPage", "consent"},
+}
+
+type SchemaError struct {
+	Path         string
+	Interstitial string
+	Snippet      string
+}
+
+func (e *SchemaError) Error() string {
+	if e.Interstitial != "" {
+		return fmt.Sprintf("got %s page instead of \"%s\": %s", e.Interstitial, e.Path, e.Snippet)
+	}
+
+	return fmt.Sprintf("missing \"%s\" in page: %s", e.Path, e.Snippet)
+}
+
+func newSchemaError(path string, jsonString string) *SchemaError {
+	return &SchemaError{
+		Path:         path,
+		Interstitial: detectInterstitial(jsonString),
+		Snippet:      snippet(jsonString),
+	}
+}
+
+func decodeSharedData(jsonString string) (*sharedDataJsonType, error) {
+	sharedData := &sharedDataJsonType{raw: jsonString}
+	if err := json.Unmarshal([]byte(jsonString), sharedData); err != nil {
+		return nil, err
+	}
+
+	return sharedData, nil
+}
+
+func (s *sharedDataJsonType) profileUser() (*userJsonType, error) {
+	if len(s.EntryData.ProfilePage) == 0 {
+		return nil, newSchemaError("entry_data.ProfilePage[0]", s.raw)
+	}
+
+	user := s.EntryData.ProfilePage[0].GraphQL.User
+	if user == nil {
+		return nil, newSchemaError("entry_data.ProfilePage[0].graphql.user", s.raw)
+	}
+
+	return user, nil
+}
+
+func (s *sharedDataJsonType) hashtag() (*hashtagJsonType, error) {
+	if len(s.EntryData.TagPage) == 0 {
+		return nil, newSchemaError("entry_data.TagPage[0]", s.raw)
+	}
+
+	hashtag := s.EntryData.TagPage[0].GraphQL.Hashtag
+	if hashtag == nil {
+		return nil, newSchemaError("entry_data.TagPage[0].graphql.hashtag", s.raw)
+	}
+
+	return hashtag, nil
+}
+
+func (s *sharedDataJsonType) location() (*locationJsonType, error) {
+	if len(s.EntryData.LocationsPage) == 0 {
+		return nil, newSchemaError("entry_data.LocationsPage[0]", s.raw)
+	}
+
+	location := s.EntryData.LocationsPage[0].GraphQL.Location
+	if location == nil {
+		return nil, newSchemaError("entry_data.LocationsPage[0].graphql.location", s.raw)
+	}
+
+	return location, nil
+}
+
+func (s *sharedDataJsonType) shortcodeMedia() (*shortcodeMediaJsonType, error) {
+	if len(s.EntryData.PostPage) == 0 {
+		return nil, newSchemaError("entry_data.PostPage[0]", s.raw)
+	}
+
+	media := s.EntryData.PostPage[0].Graphql.ShortcodeMedia
+	if media == nil {
+		return nil, newSchemaError("entry_data.PostPage[0].graphql.shortcode_media", s.raw)
+	}
+
+	return media, nil
+}
+
+func detectInterstitial(jsonString string) string {
+	page := struct {
+		EntryData map[string]json.RawMessage `json:"entry_data"`
+	}{}
+	if err := json.Unmarshal([]byte(jsonString), &page); err != nil {
+		return ""
+	}
+
+	for _, interstitial := range interstitialPages {
+		if _, ok := page.EntryData[interstitial.key]; ok {
+			return interstitial.name
+		}
+	}
+
+	return ""
+}
+
+func snippet(s string) string {
+	runes := []rune(s)
+	if len(runes) <= schemaSnippetLength {
+		return s
+	}
+
+	return string(runes[:schemaSnippetLength]) + "..."
+}
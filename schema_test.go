@@ -0,0 +1,80 @@
+package crawler
+
+import "testing"
+
+func TestProfileUser(t *testing.T) {
+	cases := []struct {
+		json         string
+		path         string
+		interstitial string
+	}{
+		{`{"entry_data":{"ProfilePage":[{"graphql":{"user":{"id":"1"}}}]}}`, "", ""},
+		{`{"entry_data":{"ProfilePage":[]}}`, "entry_data.ProfilePage[0]", ""},
+		{`{"entry_data":{"ProfilePage":[{"graphql":{}}]}}`, "entry_data.ProfilePage[0].graphql.user", ""},
+		{`{"entry_data":{"LoginAndSignupPage":[{}]}}`, "entry_data.ProfilePage[0]", "login"},
+		{`{"entry_data":{"Challenge":[{}]}}`, "entry_data.ProfilePage[0]", "challenge"},
+	}
+
+	for _, c := range cases {
+		sharedData, err := decodeSharedData(c.json)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = sharedData.profileUser()
+		if c.path == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", c.json, err)
+			}
+			continue
+		}
+
+		schemaErr, ok := err.(*SchemaError)
+		if !ok {
+			t.Errorf("%s: expected *SchemaError, got %v", c.json, err)
+			continue
+		}
+		if schemaErr.Path != c.path || schemaErr.Interstitial != c.interstitial {
+			t.Errorf("%s: got path %q interstitial %q", c.json, schemaErr.Path, schemaErr.Interstitial)
+		}
+	}
+}
+
+func TestShortcodeMedia(t *testing.T) {
+	sharedData, err := decodeSharedData(`{"entry_data":{"ConsentPage":[{}]}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = sharedData.shortcodeMedia()
+	schemaErr, ok := err.(*SchemaError)
+	if !ok || schemaErr.Path != "entry_data.PostPage[0]" || schemaErr.Interstitial != "consent" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestExtractSharedDataJsonString(t *testing.T) {
+	cases := []struct {
+		html string
+		json string
+	}{
+		{`<script>window._sharedData = {"a":1};</script>`, `{"a":1}`},
+		{`<script>window._sharedData={"a":1}</script>`, `{"a":1}`},
+		{`<script>window._sharedData</script>`, ""},
+		{`<script>window._sharedData;</script>`, ""},
+		{`<script>var a = 1;</script>`, ""},
+	}
+
+	for _, c := range cases {
+		jsonString, err := extractSharedDataJsonString([]byte(c.html))
+		if c.json == "" {
+			if err == nil {
+				t.Errorf("%s: expected error, got %q", c.html, jsonString)
+			}
+			continue
+		}
+		if err != nil || jsonString != c.json {
+			t.Errorf("%s: got %q, %v", c.html, jsonString, err)
+		}
+	}
+}
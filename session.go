@@ -0,0 +1,407 @@
+package crawler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/kouheiszk/ig-crawler/pkg/output"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const SessionCookieName = "sessionid"
+const CsrfCookieName = "csrftoken"
+
+type sessionFormat int
+
+const (
+	cookiesTxtFormat sessionFormat = iota
+	cookiesJSONFormat
+)
+
+type Session struct {
+	sync.Mutex
+	cookies map[string]*http.Cookie
+	path    string
+	file    *sessionFile
+}
+
+// Instagram以外のクッキーや行は書き戻すときにそのまま残す
+type sessionFile struct {
+	format  sessionFormat
+	lines   []string                   // cookies.txt
+	entries []json.RawMessage          // JSON
+	wrapper map[string]json.RawMessage // {"cookies": [...]} 形式の他のキー
+}
+
+type SessionExpiredError struct {
+	Reason string
+}
+
+func (e *SessionExpiredError) Error() string {
+	return fmt.Sprintf("session has expired: %s", e.Reason)
+}
+
+func NewSession(cookies []*http.Cookie) *Session {
+	session := &Session{cookies: map[string]*http.Cookie{}}
+	for _, cookie := range cookies {
+		session.cookies[cookie.Name] = cookie
+	}
+
+	return session
+}
+
+// Netscape形式のcookies.txtか、ブラウザ拡張でエクスポートしたJSONを読み込む
+func LoadSession(path string) (*Session, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cookies []*http.Cookie
+	var file *sessionFile
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		cookies, file, err = parseCookiesJSON(bytes.NewReader(data))
+	} else {
+		cookies, file, err = parseCookiesTxt(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid cookie file \"%s\": %v", path, err)
+	}
+
+	session := NewSession(cookies)
+	session.path = path
+	session.file = file
+
+	if session.Cookie(SessionCookieName) == "" {
+		return nil, &SessionExpiredError{Reason: fmt.Sprintf("no %s cookie in \"%s\"", SessionCookieName, path)}
+	}
+
+	return session, nil
+}
+
+func (s *Session) Cookie(name string) string {
+	s.Lock()
+	defer s.Unlock()
+
+	if cookie, ok := s.cookies[name]; ok && !isExpiredCookie(cookie) {
+		return cookie.Value
+	}
+
+	return ""
+}
+
+// http.CookieJar
+func (s *Session) Cookies(u *url.URL) []*http.Cookie {
+	s.Lock()
+	defer s.Unlock()
+
+	if !isInstagramHost(u.Hostname()) {
+		return nil
+	}
+
+	var cookies []*http.Cookie
+	for _, cookie := range s.cookies {
+		if isExpiredCookie(cookie) {
+			continue
+		}
+		cookies = append(cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	return cookies
+}
+
+// http.CookieJar
+func (s *Session) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if !isInstagramHost(u.Hostname()) || len(cookies) == 0 {
+		return
+	}
+
+	s.Lock()
+	for _, cookie := range cookies {
+		if cookie.MaxAge > 0 {
+			cookie.Expires = time.Now().Add(time.Duration(cookie.MaxAge) * time.Second)
+		}
+		if cookie.Domain == "" {
+			cookie.Domain = ".instagram.com"
+		}
+		if cookie.Path == "" {
+			cookie.Path = "/"
+		}
+
+		if cookie.MaxAge < 0 || isExpiredCookie(cookie) {
+			delete(s.cookies, cookie.Name)
+			continue
+		}
+		s.cookies[cookie.Name] = cookie
+	}
+	s.Unlock()
+
+	if err := s.Save(); err != nil {
+		log.Print(err)
+	}
+}
+
+// 読み込んだファイルに更新されたクッキーを書き戻す
+func (s *Session) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	return s.SaveTo(s.path)
+}
+
+func (s *Session) SaveTo(path string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	file := s.file
+	if file == nil {
+		file = &sessionFile{format: cookiesTxtFormat}
+	}
+
+	buffer := &bytes.Buffer{}
+	var err error
+	if file.format == cookiesJSONFormat {
+		err = writeCookiesJSON(buffer, file, s.cookies)
+	} else {
+		err = writeCookiesTxt(buffer, file, s.cookies)
+	}
+	if err != nil {
+		return err
+	}
+
+	return output.WriteFileAtomic(path, buffer.Bytes(), 0600)
+}
+
+func (s *Session) checkRedirect(request *http.Request, via []*http.Request) error {
+	if strings.HasPrefix(request.URL.Path, "/accounts/login") || strings.HasPrefix(request.URL.Path, "/challenge") {
+		return &SessionExpiredError{Reason: fmt.Sprintf("redirected to %s", request.URL.Path)}
+	}
+
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+
+	return nil
+}
+
+// ログインを求めるページが返ってきた場合はセッション切れとして扱う
+func (c *Crawler) sessionError(err error) error {
+	if c.config.Session == nil {
+		return err
+	}
+
+	if schemaErr, ok := err.(*SchemaError); ok && (schemaErr.Interstitial == "login" || schemaErr.Interstitial == "challenge") {
+		return &SessionExpiredError{Reason: schemaErr.Error()}
+	}
+
+	return err
+}
+
+func checkLoginRequired(response []byte) error {
+	status := struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(response, &status) != nil {
+		return nil
+	}
+
+	if status.Status == "fail" && strings.Contains(status.Message, "login") {
+		return &SessionExpiredError{Reason: status.Message}
+	}
+
+	return nil
+}
+
+func isInstagramHost(host string) bool {
+	return host == "instagram.com" || strings.HasSuffix(host, ".instagram.com")
+}
+
+func isExpiredCookie(cookie *http.Cookie) bool {
+	return !cookie.Expires.IsZero() && cookie.Expires.Before(time.Now())
+}
+
+func parseCookiesTxt(r io.Reader) ([]*http.Cookie, *sessionFile, error) {
+	var cookies []*http.Cookie
+	file := &sessionFile{format: cookiesTxtFormat}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		httpOnly := false
+		if strings.HasPrefix(line, "#HttpOnly_") {
+			line = strings.TrimPrefix(line, "#HttpOnly_")
+			httpOnly = true
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			file.lines = append(file.lines, raw)
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, nil, fmt.Errorf("malformed line \"%s\"", line)
+		}
+
+		if !isInstagramHost(strings.TrimPrefix(fields[0], ".")) {
+			file.lines = append(file.lines, raw)
+			continue
+		}
+
+		cookie := &http.Cookie{
+			Domain:   fields[0],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+		if expires, err := strconv.ParseInt(fields[4], 10, 64); err == nil && expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+
+	return cookies, file, scanner.Err()
+}
+
+func writeCookiesTxt(w io.Writer, file *sessionFile, cookies map[string]*http.Cookie) error {
+	lines := file.lines
+	if len(lines) == 0 {
+		lines = []string{"# Netscape HTTP Cookie File"}
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	for _, cookie := range cookies {
+		prefix := ""
+		if cookie.HttpOnly {
+			prefix = "#HttpOnly_"
+		}
+		var expires int64
+		if !cookie.Expires.IsZero() {
+			expires = cookie.Expires.Unix()
+		}
+
+		_, err := fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			prefix, cookie.Domain, netscapeBool(strings.HasPrefix(cookie.Domain, ".")), cookie.Path,
+			netscapeBool(cookie.Secure), expires, cookie.Name, cookie.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func netscapeBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+
+	return "FALSE"
+}
+
+func parseCookiesJSON(r io.Reader) ([]*http.Cookie, *sessionFile, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file := &sessionFile{format: cookiesJSONFormat}
+	var raws []json.RawMessage
+	if err = json.Unmarshal(data, &raws); err != nil {
+		// {"cookies": [...]} 形式のエクスポートにも対応する
+		wrapper := map[string]json.RawMessage{}
+		if json.Unmarshal(data, &wrapper) != nil || json.Unmarshal(wrapper["cookies"], &raws) != nil {
+			return nil, nil, err
+		}
+		file.wrapper = wrapper
+	}
+
+	var cookies []*http.Cookie
+	for _, raw := range raws {
+		entry := cookieJsonType{}
+		if err = json.Unmarshal(raw, &entry); err != nil {
+			return nil, nil, err
+		}
+
+		if !isInstagramHost(strings.TrimPrefix(entry.Domain, ".")) {
+			file.entries = append(file.entries, raw)
+			continue
+		}
+
+		cookie := &http.Cookie{
+			Name:     entry.Name,
+			Value:    entry.Value,
+			Domain:   entry.Domain,
+			Path:     entry.Path,
+			Secure:   entry.Secure,
+			HttpOnly: entry.HttpOnly,
+		}
+		if entry.ExpirationDate > 0 {
+			cookie.Expires = time.Unix(int64(entry.ExpirationDate), 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+
+	return cookies, file, nil
+}
+
+func writeCookiesJSON(w io.Writer, file *sessionFile, cookies map[string]*http.Cookie) error {
+	entries := make([]interface{}, 0, len(file.entries)+len(cookies))
+	for _, raw := range file.entries {
+		entries = append(entries, raw)
+	}
+	for _, cookie := range cookies {
+		entry := cookieJsonType{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+		}
+		if !cookie.Expires.IsZero() {
+			entry.ExpirationDate = float64(cookie.Expires.Unix())
+		}
+		entries = append(entries, entry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if file.wrapper == nil {
+		return encoder.Encode(entries)
+	}
+
+	wrapper := map[string]interface{}{}
+	for key, value := range file.wrapper {
+		wrapper[key] = value
+	}
+	wrapper["cookies"] = entries
+
+	return encoder.Encode(wrapper)
+}
+
+type cookieJsonType struct {
+	Name           string  `json:"name"`
+	Value          string  `json:"value"`
+	Domain         string  `json:"domain"`
+	Path           string  `json:"path"`
+	ExpirationDate float64 `json:"expirationDate,omitempty"`
+	Secure         bool    `json:"secure"`
+	HttpOnly       bool    `json:"httpOnly"`
+}
@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseCookiesTxt(t *testing.T) {
+	txt := "# Netscape HTTP Cookie File\n" +
+		"#HttpOnly_.instagram.com\tTRUE\t/\tTRUE\t4102444800\tsessionid\tabc\n" +
+		".instagram.com\tTRUE\t/\tTRUE\t4102444800\tcsrftoken\txyz\n" +
+		".example.com\tTRUE\t/\tFALSE\t0\tother\tvalue\n"
+
+	cookies, file, err := parseCookiesTxt(strings.NewReader(txt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := NewSession(cookies)
+	if session.Cookie("sessionid") != "abc" || session.Cookie("csrftoken") != "xyz" || session.Cookie("other") != "" {
+		t.Errorf("unexpected cookies %v", cookies)
+	}
+
+	buffer := &bytes.Buffer{}
+	if err = writeCookiesTxt(buffer, file, session.cookies); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), ".example.com\tTRUE\t/\tFALSE\t0\tother\tvalue\n") {
+		t.Errorf("other cookies were not kept: %s", buffer.String())
+	}
+	reparsed, _, err := parseCookiesTxt(buffer)
+	if err != nil || len(reparsed) != 2 {
+		t.Errorf("round trip failed: %v %v", reparsed, err)
+	}
+}
+
+func TestParseCookiesJSON(t *testing.T) {
+	json := `[
+		{"domain": ".instagram.com", "name": "sessionid", "value": "abc", "path": "/", "expirationDate": 4102444800.5, "secure": true, "httpOnly": true},
+		{"domain": ".instagram.com", "name": "expired", "value": "old", "path": "/", "expirationDate": 1},
+		{"domain": ".example.com", "name": "other", "value": "value", "storeId": "0"}
+	]`
+
+	cookies, file, err := parseCookiesJSON(strings.NewReader(json))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := NewSession(cookies)
+	if session.Cookie("sessionid") != "abc" || session.Cookie("expired") != "" {
+		t.Errorf("unexpected cookies %v", cookies)
+	}
+
+	buffer := &bytes.Buffer{}
+	if err = writeCookiesJSON(buffer, file, session.cookies); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), `"storeId": "0"`) {
+		t.Errorf("other cookies were not kept: %s", buffer.String())
+	}
+}
+
+func TestSessionCheckRedirect(t *testing.T) {
+	request, _ := http.NewRequest("GET", "https://www.instagram.com/accounts/login/?next=/kouheiszk/", nil)
+	err := NewSession(nil).checkRedirect(request, nil)
+	if _, ok := err.(*SessionExpiredError); !ok {
+		t.Errorf("expected *SessionExpiredError, got %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package crawler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/kouheiszk/ig-crawler/pkg/output"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const UserInfoUrl = "https://i.instagram.com/api/v1/users/%s/info/"
+
+type ProfileSnapshot struct {
+	Profile
+	FetchedAt      time.Time `json:"fetched_at"`
+	ProfilePicHash string    `json:"profile_pic_hash,omitempty"`
+}
+
+type ProfileChangeKind string
+
+const (
+	UsernameChanged       ProfileChangeKind = "username_changed"
+	FullNameChanged       ProfileChangeKind = "full_name_changed"
+	BiographyChanged      ProfileChangeKind = "biography_changed"
+	ExternalUrlChanged    ProfileChangeKind = "external_url_changed"
+	FollowerCountChanged  ProfileChangeKind = "follower_count_changed"
+	FollowingCountChanged ProfileChangeKind = "following_count_changed"
+	PostCountChanged      ProfileChangeKind = "post_count_changed"
+	ProfilePicChanged     ProfileChangeKind = "profile_pic_changed"
+	BecamePrivate         ProfileChangeKind = "became_private"
+	BecamePublic          ProfileChangeKind = "became_public"
+)
+
+type ProfileChange struct {
+	Kind   ProfileChangeKind `json:"kind"`
+	UserId string            `json:"user_id"`
+	Old    string            `json:"old,omitempty"`
+	New    string            `json:"new,omitempty"`
+	Delta  int               `json:"delta,omitempty"`
+	At     time.Time         `json:"at"`
+}
+
+func (c ProfileChange) String() string {
+	switch {
+	case c.Delta != 0:
+		return fmt.Sprintf("%s %s: %s -> %s (%+d)", c.UserId, c.Kind, c.Old, c.New, c.Delta)
+	case c.Old != "" || c.New != "":
+		return fmt.Sprintf("%s %s: %q -> %q", c.UserId, c.Kind, c.Old, c.New)
+	default:
+		return fmt.Sprintf("%s %s", c.UserId, c.Kind)
+	}
+}
+
+func FetchProfileSnapshot(config *Config) (*ProfileSnapshot, error) {
+	return FetchProfileSnapshotWithContext(context.Background(), config)
+}
+
+func FetchProfileSnapshotWithContext(ctx context.Context, config *Config) (*ProfileSnapshot, error) {
+	crawler := NewCrawler(config)
+
+	if _, _, err := crawler.loadProfile(ctx); err != nil {
+		return nil, err
+	}
+
+	snapshot := &ProfileSnapshot{
+		Profile:   *newProfile(crawler.user),
+		FetchedAt: time.Now(),
+	}
+
+	// URLは署名付きで毎回変わるので、画像の中身で比較する
+	if snapshot.ProfilePicUrlHd != "" {
+		image, err := crawler.fetch(ctx, snapshot.ProfilePicUrlHd)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't fetch profile image")
+		}
+		hash := sha256.Sum256(image)
+		snapshot.ProfilePicHash = hex.EncodeToString(hash[:])
+	}
+
+	return snapshot, nil
+}
+
+// ユーザー名が変わるとプロフィールページが見つからなくなるので、ユーザーIDから今のユーザー名を引く
+func ResolveUsername(ctx context.Context, config *Config, userId string) (string, error) {
+	crawler := NewCrawler(config)
+
+	response, err := crawler.fetchWithHeaders(ctx, fmt.Sprintf(UserInfoUrl, userId), map[string]string{"x-ig-app-id": InstagramAppId})
+	if err != nil {
+		return "", err
+	}
+
+	info := userInfoJsonType{}
+	if err = json.Unmarshal(response, &info); err != nil {
+		return "", errors.Wrapf(err, "invalid user info json \"%s\"", string(response))
+	}
+
+	if info.User.Username == "" {
+		return "", fmt.Errorf("couldn't resolve username of user %s", userId)
+	}
+
+	return info.User.Username, nil
+}
+
+func DiffSnapshots(previous *ProfileSnapshot, current *ProfileSnapshot) []ProfileChange {
+	var changes []ProfileChange
+	change := func(kind ProfileChangeKind, o string, n string) {
+		changes = append(changes, ProfileChange{Kind: kind, UserId: current.Id, Old: o, New: n, At: current.FetchedAt})
+	}
+	count := func(kind ProfileChangeKind, o int, n int) {
+		changes = append(changes, ProfileChange{Kind: kind, UserId: current.Id, Old: fmt.Sprint(o), New: fmt.Sprint(n), Delta: n - o, At: current.FetchedAt})
+	}
+
+	if previous.Username != current.Username {
+		change(UsernameChanged, previous.Username, current.Username)
+	}
+	if previous.FullName != current.FullName {
+		change(FullNameChanged, previous.FullName, current.FullName)
+	}
+	if previous.Biography != current.Biography {
+		change(BiographyChanged, previous.Biography, current.Biography)
+	}
+	if previous.ExternalUrl != current.ExternalUrl {
+		change(ExternalUrlChanged, previous.ExternalUrl, current.ExternalUrl)
+	}
+	if previous.FollowerCount != current.FollowerCount {
+		count(FollowerCountChanged, previous.FollowerCount, current.FollowerCount)
+	}
+	if previous.FollowingCount != current.FollowingCount {
+		count(FollowingCountChanged, previous.FollowingCount, current.FollowingCount)
+	}
+	if previous.PostCount != current.PostCount {
+		count(PostCountChanged, previous.PostCount, current.PostCount)
+	}
+	if previous.ProfilePicHash != "" && current.ProfilePicHash != "" && previous.ProfilePicHash != current.ProfilePicHash {
+		change(ProfilePicChanged, previous.ProfilePicHash, current.ProfilePicHash)
+	}
+	if !previous.IsPrivate && current.IsPrivate {
+		change(BecamePrivate, "", "")
+	}
+	if previous.IsPrivate && !current.IsPrivate {
+		change(BecamePublic, "", "")
+	}
+
+	return changes
+}
+
+// ユーザー名は変わりうるので、ユーザーIDごとにスナップショットを保存する
+type SnapshotStore struct {
+	Dir string
+}
+
+func NewSnapshotStore(dir string) *SnapshotStore {
+	return &SnapshotStore{Dir: dir}
+}
+
+func (s *SnapshotStore) Save(snapshot *ProfileSnapshot) error {
+	if snapshot.Id == "" {
+		return fmt.Errorf("snapshot has no user id")
+	}
+
+	dir := filepath.Join(s.Dir, snapshot.Id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	bytes, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%d.json", snapshot.FetchedAt.UnixNano()))
+	return output.WriteFileAtomic(path, bytes, 0644)
+}
+
+func (s *SnapshotStore) History(userId string) ([]ProfileSnapshot, error) {
+	paths, err := s.paths(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []ProfileSnapshot
+	for _, path := range paths {
+		snapshot, err := readSnapshot(path)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+
+	return snapshots, nil
+}
+
+func (s *SnapshotStore) Latest(userId string) (*ProfileSnapshot, error) {
+	paths, err := s.paths(userId)
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+
+	return readSnapshot(paths[len(paths)-1])
+}
+
+// ファイル名の取得時刻で古い順に並べる
+func (s *SnapshotStore) paths(userId string) ([]string, error) {
+	files, err := ioutil.ReadDir(filepath.Join(s.Dir, userId))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		path      string
+		fetchedAt int64
+	}
+	var entries []entry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		fetchedAt, err := strconv.ParseInt(strings.TrimSuffix(file.Name(), ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry{filepath.Join(s.Dir, userId, file.Name()), fetchedAt})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].fetchedAt < entries[j].fetchedAt
+	})
+
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		paths = append(paths, e.path)
+	}
+
+	return paths, nil
+}
+
+func readSnapshot(path string) (*ProfileSnapshot, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &ProfileSnapshot{}
+	if err = json.Unmarshal(bytes, snapshot); err != nil {
+		return nil, errors.Wrapf(err, "invalid snapshot \"%s\"", path)
+	}
+
+	return snapshot, nil
+}
+
+type userInfoJsonType struct {
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+}
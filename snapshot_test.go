@@ -0,0 +1,82 @@
+package crawler
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	old := &ProfileSnapshot{
+		Profile: Profile{
+			Id:            "1",
+			Username:      "before",
+			Biography:     "hello",
+			FollowerCount: 100,
+		},
+		ProfilePicHash: "aaa",
+	}
+	current := &ProfileSnapshot{
+		Profile: Profile{
+			Id:            "1",
+			Username:      "after",
+			Biography:     "hello",
+			FollowerCount: 90,
+			IsPrivate:     true,
+		},
+		ProfilePicHash: "bbb",
+	}
+
+	changes := DiffSnapshots(old, current)
+	kinds := map[ProfileChangeKind]ProfileChange{}
+	for _, change := range changes {
+		kinds[change.Kind] = change
+	}
+
+	if len(changes) != 4 {
+		t.Errorf("unexpected changes %v", changes)
+	}
+	if kinds[UsernameChanged].Old != "before" || kinds[UsernameChanged].New != "after" {
+		t.Errorf("unexpected username change %v", kinds[UsernameChanged])
+	}
+	if kinds[FollowerCountChanged].Delta != -10 {
+		t.Errorf("unexpected follower change %v", kinds[FollowerCountChanged])
+	}
+	if _, ok := kinds[ProfilePicChanged]; !ok {
+		t.Error("profile pic change not detected")
+	}
+	if _, ok := kinds[BecamePrivate]; !ok {
+		t.Error("private change not detected")
+	}
+}
+
+func TestSnapshotStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewSnapshotStore(dir)
+	now := time.Now()
+	for i, username := range []string{"first", "second"} {
+		snapshot := &ProfileSnapshot{Profile: Profile{Id: "1", Username: username}, FetchedAt: now.Add(time.Duration(i) * time.Second)}
+		if err := store.Save(snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "1", "notes.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := store.Latest("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest == nil || latest.Username != "second" {
+		t.Errorf("unexpected latest snapshot %v", latest)
+	}
+}
@@ -0,0 +1,115 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+)
+
+const HighlightReelsQueryHash = "7c16654f22c819fb63d1183034a5162f"
+const HighlightItemsQueryHash = "45246d3fe16ccc6577e0bd297a5db1ab"
+const StoryItemsQueryHash = "303a4ae99711322310f25250d988f3b7"
+
+type reel struct {
+	id          string
+	highlightId string
+	title       string
+}
+
+func (c *Crawler) handleHighlightReels(ctx context.Context) error {
+	params := "{\"user_id\":\"" + c.userId + "\",\"include_chaining\":false,\"include_reel\":false,\"include_suggested_users\":false,\"include_logged_out_extras\":false,\"include_highlight_reels\":true}"
+	reelsJson := highlightReelsJsonType{}
+	if err := c.query(ctx, HighlightReelsQueryHash, params, &reelsJson); err != nil {
+		return err
+	}
+
+	for _, element := range reelsJson.Data.User.EdgeHighlightReels.Edges {
+		c.reelChan <- reel{
+			id:          element.Node.Id,
+			highlightId: element.Node.Id,
+			title:       element.Node.Title,
+		}
+	}
+
+	return nil
+}
+
+func (c *Crawler) handleReel(ctx context.Context, r reel) error {
+	ids, err := json.Marshal([]string{r.id})
+	if err != nil {
+		return err
+	}
+
+	// ハイライトとストーリーはクエリが異なる
+	queryHash := StoryItemsQueryHash
+	params := "{\"reel_ids\":" + string(ids) + ",\"precomposed_overlay\":false}"
+	if r.highlightId != "" {
+		queryHash = HighlightItemsQueryHash
+		params = "{\"reel_ids\":[],\"tag_names\":[],\"location_ids\":[],\"highlight_reel_ids\":" + string(ids) + ",\"precomposed_overlay\":false}"
+	}
+
+	reelsJson := reelsMediaJsonType{}
+	if err := c.query(ctx, queryHash, params, &reelsJson); err != nil {
+		return err
+	}
+
+	for _, reelMedia := range reelsJson.Data.ReelsMedia {
+		for _, item := range reelMedia.Items {
+			if item.Timestamp <= c.config.After {
+				continue
+			}
+
+			resource := Resource{
+				Url:            item.DisplaySrc,
+				Timestamp:      item.Timestamp,
+				IsVideo:        item.IsVideo,
+				HighlightId:    r.highlightId,
+				HighlightTitle: r.title,
+				ExpiresAt:      item.ExpiringAt,
+			}
+			if item.IsVideo && len(item.VideoResources) > 0 {
+				// 最後の要素が最も高画質
+				resource.Url = item.VideoResources[len(item.VideoResources)-1].Src
+			}
+
+			c.resourceChan <- resource
+		}
+	}
+
+	return nil
+}
+
+type highlightReelsJsonType struct {
+	Data struct {
+		User struct {
+			EdgeHighlightReels struct {
+				Edges []struct {
+					Node struct {
+						Id    string `json:"id"`
+						Title string `json:"title"`
+					} `json:"node"`
+				} `json:"edges"`
+			} `json:"edge_highlight_reels"`
+		} `json:"user"`
+	} `json:"data"`
+}
+
+type reelsMediaJsonType struct {
+	Data struct {
+		ReelsMedia []struct {
+			Id         string `json:"id"`
+			ExpiringAt int32  `json:"expiring_at"`
+			Items      []struct {
+				Typename       string `json:"__typename"`
+				Id             string `json:"id"`
+				IsVideo        bool   `json:"is_video"`
+				Timestamp      int32  `json:"taken_at_timestamp"`
+				ExpiringAt     int32  `json:"expiring_at_timestamp"`
+				DisplaySrc     string `json:"display_url"`
+				VideoResources []struct {
+					Src     string `json:"src"`
+					Profile string `json:"profile"`
+				} `json:"video_resources"`
+			} `json:"items"`
+		} `json:"reels_media"`
+	} `json:"data"`
+}
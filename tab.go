@@ -0,0 +1,142 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"net/url"
+)
+
+const TaggedQueryHash = "ff260833edf142911047af6024eb634a"
+const IGTVQueryHash = "bc78b344a68ed16dd5d7f264681c4c76"
+const ReelsUrl = "https://www.instagram.com/api/v1/clips/user/"
+const InstagramAppId = "936619743392459"
+
+func (c *Crawler) handleTabPage(ctx context.Context, p page) error {
+	queryHash := TaggedQueryHash
+	if p.kind == igtvPage {
+		queryHash = IGTVQueryHash
+	}
+
+	params := "{\"id\":\"" + c.userId + "\",\"first\":" + "12" + ",\"after\":\"" + p.cursor + "\"}"
+	pageJson := tabPageJsonType{}
+	if err := c.query(ctx, queryHash, params, &pageJson); err != nil {
+		return err
+	}
+
+	if p.kind == igtvPage {
+		c.handleMedia(ctx, pageJson.Data.User.IGTV, p.kind)
+	} else {
+		c.handleMedia(ctx, pageJson.Data.User.Tagged, p.kind)
+	}
+
+	return nil
+}
+
+// リールはGraphQLではなくWeb APIから取得する
+func (c *Crawler) handleReelsPage(ctx context.Context, p page) error {
+	form := url.Values{}
+	form.Set("target_user_id", c.userId)
+	form.Set("page_size", "12")
+	if p.cursor != "" {
+		form.Set("max_id", p.cursor)
+	}
+
+	response, err := c.postForm(ctx, ReelsUrl, form, map[string]string{"x-ig-app-id": InstagramAppId})
+	if err != nil {
+		return err
+	}
+
+	pageJson := reelsPageJsonType{}
+	if err = json.Unmarshal(response, &pageJson); err != nil {
+		return errors.Wrapf(err, "invalid reels json \"%s\"", string(response))
+	}
+
+	hasNextPage := pageJson.PagingInfo.MoreAvailable
+	posts := 0
+	for _, item := range pageJson.Items {
+		media := item.Media
+		if media.TakenAt <= c.config.After {
+			hasNextPage = false
+			continue
+		}
+
+		if _, loaded := c.shortcodes.LoadOrStore(p.kind.tab()+":"+media.Code, true); loaded {
+			continue
+		}
+
+		resource := Resource{
+			Timestamp: media.TakenAt,
+			IsVideo:   len(media.VideoVersions) > 0,
+			Shortcode: media.Code,
+			Tab:       p.kind.tab(),
+			ViewCount: media.ViewCount,
+			PlayCount: media.PlayCount,
+		}
+		if resource.IsVideo {
+			resource.Url = media.VideoVersions[0].Url
+		} else if len(media.ImageVersions.Candidates) > 0 {
+			resource.Url = media.ImageVersions.Candidates[0].Url
+		}
+
+		c.store.addPost(&Post{
+			Id:        media.Id,
+			Shortcode: media.Code,
+			Url:       postUrl(media.Code),
+			Caption:   media.Caption.Text,
+			Timestamp: media.TakenAt,
+			IsVideo:   resource.IsVideo,
+			VideoUrl:  resource.Url,
+			LikeCount: media.LikeCount,
+			OwnerId:   c.userId,
+			Owner:     c.config.Username,
+		})
+
+		c.resourceChan <- resource
+		posts++
+	}
+	c.report(ProgressEvent{Kind: PageFetched, Tab: p.kind.tab(), Posts: posts})
+
+	if hasNextPage && pageJson.PagingInfo.MaxId != "" {
+		c.pageChan <- page{p.kind, pageJson.PagingInfo.MaxId}
+	}
+
+	return nil
+}
+
+type tabPageJsonType struct {
+	Data struct {
+		User struct {
+			Tagged mediaJsonType `json:"edge_user_to_photos_of_you"`
+			IGTV   mediaJsonType `json:"edge_felix_video_timeline"`
+		} `json:"user"`
+	} `json:"data"`
+}
+
+type reelsPageJsonType struct {
+	Items []struct {
+		Media struct {
+			Id            string `json:"id"`
+			Code          string `json:"code"`
+			TakenAt       int32  `json:"taken_at"`
+			LikeCount     int    `json:"like_count"`
+			PlayCount     int    `json:"play_count"`
+			ViewCount     int    `json:"view_count"`
+			VideoVersions []struct {
+				Url string `json:"url"`
+			} `json:"video_versions"`
+			ImageVersions struct {
+				Candidates []struct {
+					Url string `json:"url"`
+				} `json:"candidates"`
+			} `json:"image_versions2"`
+			Caption struct {
+				Text string `json:"text"`
+			} `json:"caption"`
+		} `json:"media"`
+	} `json:"items"`
+	PagingInfo struct {
+		MaxId         string `json:"max_id"`
+		MoreAvailable bool   `json:"more_available"`
+	} `json:"paging_info"`
+}
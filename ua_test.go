@@ -0,0 +1,88 @@
+package crawler
+
+import (
+	"context"
+	"github.com/kouheiszk/ig-crawler/pkg/ua"
+	"net/http"
+	"testing"
+)
+
+func TestBrowserHeaders(t *testing.T) {
+	profile := ua.SelectProfiles(ua.Chrome, ua.Windows)[0]
+	graphql, _ := http.NewRequest("GET", "https://www.instagram.com/graphql/query/?query_hash=x", nil)
+	page, _ := http.NewRequest("GET", "https://www.instagram.com/kouheiszk/", nil)
+
+	crawler := NewCrawler(&Config{Profile: profile})
+	if crawler.config.UserAgent != profile.UserAgent {
+		t.Fatalf("profile user agent is not used: %s", crawler.config.UserAgent)
+	}
+	if headers := crawler.browserHeaders(graphql); headers["sec-fetch-mode"] != "cors" || headers["sec-ch-ua-platform"] != `"Windows"` {
+		t.Errorf("unexpected graphql headers %v", headers)
+	}
+	if headers := crawler.browserHeaders(page); headers["sec-fetch-mode"] != "navigate" {
+		t.Errorf("unexpected page headers %v", headers)
+	}
+
+	// 独自のUser-Agentには解析した結果に合うヘッダを付ける
+	custom := "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/139.0.0.0 Safari/537.36"
+	crawler = NewCrawler(&Config{UserAgent: custom})
+	if headers := crawler.browserHeaders(graphql); headers["user-agent"] != custom || headers["sec-ch-ua"] != `"Google Chrome";v="139", "Not?A_Brand";v="8", "Chromium";v="139"` {
+		t.Errorf("unexpected custom headers %v", headers)
+	}
+}
+
+func TestRejectUserAgent(t *testing.T) {
+	for _, userAgent := range []string{"custom", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_14_1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/70.0.3538.77 Safari/537.36"} {
+		crawler := NewCrawler(&Config{UserAgent: userAgent})
+		crawler.client = &http.Client{Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+			t.Fatal("must not send a request")
+			return nil, nil
+		})}
+		if _, err := crawler.fetch(context.Background(), "https://www.instagram.com/kouheiszk/"); err == nil {
+			t.Errorf("%s: expected error", userAgent)
+		}
+	}
+}
+
+func TestRotatorStickyToSession(t *testing.T) {
+	rotator, err := ua.NewRotator(ua.PerRequest, ua.SelectProfiles("", ""), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, _ := http.NewRequest("GET", "https://www.instagram.com/graphql/query/?query_hash=x", nil)
+
+	session := NewSession(nil)
+	first := NewCrawler(&Config{Rotator: rotator, Session: session})
+	second := NewCrawler(&Config{Rotator: rotator, Session: session})
+	if first.config.UserAgent != second.config.UserAgent {
+		t.Error("crawls sharing a session must use the same user agent")
+	}
+	for i := 0; i < 10; i++ {
+		if first.browserHeaders(request)["user-agent"] != first.config.UserAgent {
+			t.Fatal("user agent changed during a session")
+		}
+	}
+
+	anonymous := NewCrawler(&Config{Rotator: rotator})
+	seen := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		seen[anonymous.browserHeaders(request)["user-agent"]] = true
+	}
+	if len(seen) < 2 {
+		t.Error("per-request strategy must rotate without a session")
+	}
+}
+
+func TestRotatorAdvancesOncePerCrawl(t *testing.T) {
+	profiles := ua.SelectProfiles("", "")
+	rotator, err := ua.NewRotator(ua.RoundRobin, profiles, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// セッションのあるクロールで選ぶのは1回だけ
+	NewCrawler(&Config{Rotator: rotator, Session: NewSession(nil)})
+	if crawler := NewCrawler(&Config{Rotator: rotator}); crawler.config.Profile != profiles[1] {
+		t.Errorf("round robin must advance once per crawl, got %s", crawler.config.UserAgent)
+	}
+}